@@ -1,25 +1,53 @@
 package main
 
 import (
+	"encoding/hex"
 	"math/big"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"word-of-wisdom/internal/bootstrap"
+	"word-of-wisdom/internal/domain"
 	"word-of-wisdom/internal/kit"
+	"word-of-wisdom/internal/pow"
+	"word-of-wisdom/internal/repository"
 )
 
 func main() {
+	// Configure logging before anything else runs, so even bootstrap.New's
+	// Init logs go through the requested sinks
+	if err := kit.InitLogger(kit.Config{
+		Format:     getEnv("LOG_FORMAT", kit.FormatJSON),
+		Sinks:      getEnvList("LOG_SINKS", nil),
+		SyslogAddr: getEnv("SYSLOG_ADDR", ""),
+		LogFile:    getEnv("LOG_FILE", ""),
+	}); err != nil {
+		kit.Logger.Fatal("failed to initialize logger", err)
+	}
+
 	// Create and configure application
 	app := bootstrap.New()
 
 	// Configure the application
 	app.Config.Address = getEnv("SERVER_ADDRESS", ":8080")
-	app.Config.Secret = []byte(getEnv("SECRET_KEY", "your-secret-key-for-signing-challenges"))
-	app.Config.Complexity = big.NewInt(getEnvInt("POW_COMPLEXITY", 100000))
+	app.Config.Secrets, app.Config.ActiveKeyID = getSecretKeys("SECRET_KEYS", "ACTIVE_KEY_ID")
+	// POW_MIN_COMPLEXITY/POW_MAX_COMPLEXITY are leading-zero-bit counts (see
+	// domain.ComplexityFromBits), not leading zero bytes: a SHA-256 digest
+	// only has 256 bits, so values here must stay well under that
+	app.Config.MinComplexity = big.NewInt(getEnvInt("POW_MIN_COMPLEXITY", 16))
+	app.Config.MaxComplexity = big.NewInt(getEnvInt("POW_MAX_COMPLEXITY", 24))
 	app.Config.ExpirationTime = time.Duration(getEnvInt("CHALLENGE_EXPIRATION_SECONDS", 300)) * time.Second
+	app.Config.EnabledProviders = getEnvList("POW_ENABLED_ALGORITHMS", nil)
+	app.Config.DefaultAlgorithm = getEnv("POW_ALGORITHM", pow.AlgorithmSHA256)
+	app.Config.MaxAttempts = int(getEnvInt("CHALLENGE_MAX_ATTEMPTS", 5))
+	app.Config.MaxConnections = int(getEnvInt("MAX_CONNECTIONS", 10000))
+	app.Config.StorageDriver = getEnv("STORAGE_DRIVER", repository.DriverMemory)
+	app.Config.BoltPath = getEnv("BOLT_PATH", "word-of-wisdom.db")
+	app.Config.StatelessChallenges = getEnvBool("STATELESS_CHALLENGES", false)
 
 	// Initialize the application
 	if err := app.Init(); err != nil {
@@ -58,6 +86,56 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvList gets a comma-separated environment variable as a string slice
+// or returns a default value
+func getEnvList(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue
+	}
+	return strings.Split(value, ",")
+}
+
+// getEnvBool gets an environment variable as bool (accepting anything
+// strconv.ParseBool understands, e.g. "1"/"true") or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+// getSecretKeys parses keysEnv as a comma-separated "id:hex,id:hex,..."
+// keyring and activeKeyEnv as the ID Sign should use, falling back to a
+// single development key when keysEnv isn't set. An entry that fails to
+// hex-decode is fatal, since a misconfigured secret would otherwise sign
+// and verify challenges with the wrong key silently
+func getSecretKeys(keysEnv, activeKeyEnv string) ([]domain.SecretKey, string) {
+	raw, exists := os.LookupEnv(keysEnv)
+	if !exists || raw == "" {
+		return []domain.SecretKey{{ID: "default", Key: []byte("your-secret-key-for-signing-challenges")}}, "default"
+	}
+
+	entries := strings.Split(raw, ",")
+	keys := make([]domain.SecretKey, 0, len(entries))
+	for _, entry := range entries {
+		id, hexKey, ok := strings.Cut(entry, ":")
+		if !ok {
+			kit.Logger.Fatal("invalid " + keysEnv + " entry, expected id:hex")
+		}
+		key, err := hex.DecodeString(hexKey)
+		if err != nil {
+			kit.Logger.Fatal("invalid "+keysEnv+" entry: key is not valid hex", err)
+		}
+		keys = append(keys, domain.SecretKey{ID: id, Key: key})
+	}
+
+	activeID := getEnv(activeKeyEnv, keys[0].ID)
+	return keys, activeID
+}
+
 // getEnvInt gets an environment variable as int or returns a default value
 func getEnvInt(key string, defaultValue int64) int64 {
 	if value, exists := os.LookupEnv(key); exists {
@@ -2,30 +2,101 @@ package bootstrap
 
 import (
 	"context"
+	"io"
 	"math/big"
 	"time"
 
+	"word-of-wisdom/internal/difficulty"
 	"word-of-wisdom/internal/domain"
 	"word-of-wisdom/internal/kit"
+	"word-of-wisdom/internal/pow"
 	"word-of-wisdom/internal/repository"
 	"word-of-wisdom/internal/service"
 	"word-of-wisdom/internal/transport/tcp"
 )
 
+// complexityRetargetWindow and complexityTargetRate tune the
+// AdaptiveComplexityController's sliding-window retargeting. They aren't
+// exposed as config because, unlike the min/max bounds, picking good values
+// requires load-testing this specific deployment, not just per-install
+// preference
+const (
+	complexityRetargetWindow = 10 * time.Second
+	complexityTargetRate     = 5.0 // desired solved+rejected challenges per second
+)
+
 // App represents the main application container
 type App struct {
 	// Configuration
 	Config struct {
-		Address          string        // TCP server address
-		Secret          []byte        // Secret for challenge signing
-		Complexity      *big.Int      // PoW complexity
-		ExpirationTime  time.Duration // Challenge expiration time
+		Address        string        // TCP server address
+		ExpirationTime time.Duration // Challenge expiration time
+
+		// Secrets and ActiveKeyID make up the signing Keyring: Sign uses
+		// the Secrets entry whose ID matches ActiveKeyID, while
+		// VerifySignature accepts a signature produced by any of them.
+		// Rotating secrets is adding a new entry, flipping ActiveKeyID to
+		// it, and only removing the old entry once its tokens have expired
+		Secrets     []domain.SecretKey
+		ActiveKeyID string
+
+		// MinComplexity and MaxComplexity bound the PoW complexity (a
+		// leading-zero-bit count, see domain.ComplexityFromBits) the
+		// AdaptiveComplexityController may retarget to
+		MinComplexity *big.Int
+		MaxComplexity *big.Int
+
+		// EnabledProviders lists the ChallengeProvider Type()s to register,
+		// e.g. []string{"sha256", "scrypt"}. Empty means all built-ins
+		EnabledProviders []string
+
+		// DefaultAlgorithm is the provider used when a client doesn't
+		// request a specific one. Must be one of EnabledProviders
+		DefaultAlgorithm string
+
+		// MaxAttempts caps how many times a client may retry Verify for a
+		// given challenge before it becomes permanently invalid
+		MaxAttempts int
+
+		// MaxConnections caps how many TCP connections the server handles at
+		// once. A connection accepted beyond this limit is recorded as
+		// domain.OutcomeRejected against ComplexityController and closed
+		// without being served, so a flood of connections feeds into PoW
+		// retargeting the same way a flood of failed solutions would
+		MaxConnections int
+
+		// StorageDriver selects the repository backend: "memory" (the
+		// default) or "bolt" for a BoltDB-backed store that survives restarts
+		StorageDriver string
+
+		// BoltPath is the BoltDB file path used when StorageDriver is
+		// "bolt". Challenges and quotes are kept in separate files
+		BoltPath string
+
+		// StatelessChallenges selects the ChallengeService implementation.
+		// false (the default) uses the repository-backed service, keeping
+		// every issued challenge in StorageDriver's ChallengeRepository
+		// until it's solved or swept. true uses StatelessChallengeService,
+		// which signs the whole challenge state into the token handed to
+		// the client and only remembers solved challenge ids, in
+		// ReplayCache, until they expire
+		StatelessChallenges bool
 	}
 
-	// Repositories
+	// Repositories. ChallengeRepository is nil when Config.StatelessChallenges
+	// is true, since StatelessChallengeService has no use for one
 	ChallengeRepository domain.ChallengeRepository
 	QuoteRepository     domain.QuoteRepository
 
+	// ReplayCache backs StatelessChallengeService's solved-token tracking.
+	// Only set when Config.StatelessChallenges is true
+	ReplayCache domain.ReplayCache
+
+	// ComplexityController supplies the PoW complexity ChallengeService.Generate
+	// uses, retargeted to recent server load. Exposed here so a future
+	// metrics endpoint can scrape ComplexityController.Current()
+	ComplexityController domain.AdaptiveComplexityController
+
 	// Services
 	ChallengeService domain.ChallengeService
 	QuoteService     domain.QuoteService
@@ -45,41 +116,146 @@ func (a *App) Init() error {
 	if a.Config.Address == "" {
 		a.Config.Address = ":8080"
 	}
-	if a.Config.Secret == nil {
-		a.Config.Secret = []byte("default-secret-key")
+	if len(a.Config.Secrets) == 0 {
+		a.Config.Secrets = []domain.SecretKey{{ID: "default", Key: []byte("default-secret-key")}}
+	}
+	if a.Config.ActiveKeyID == "" {
+		a.Config.ActiveKeyID = a.Config.Secrets[0].ID
 	}
-	if a.Config.Complexity == nil {
-		a.Config.Complexity = big.NewInt(100000) // Default complexity
+	if a.Config.MinComplexity == nil {
+		a.Config.MinComplexity = big.NewInt(16)
+	}
+	if a.Config.MaxComplexity == nil {
+		a.Config.MaxComplexity = big.NewInt(24)
 	}
 	if a.Config.ExpirationTime == 0 {
 		a.Config.ExpirationTime = 5 * time.Minute
 	}
+	if a.Config.DefaultAlgorithm == "" {
+		a.Config.DefaultAlgorithm = pow.AlgorithmSHA256
+	}
+	if a.Config.MaxAttempts == 0 {
+		a.Config.MaxAttempts = 5
+	}
+	if a.Config.MaxConnections == 0 {
+		a.Config.MaxConnections = 10000
+	}
+	if a.Config.StorageDriver == "" {
+		a.Config.StorageDriver = repository.DriverMemory
+	}
+	if a.Config.BoltPath == "" {
+		a.Config.BoltPath = "word-of-wisdom.db"
+	}
 
 	// Initialize repositories
-	a.ChallengeRepository = repository.NewChallengeMemoryRepository()
-	a.QuoteRepository = repository.NewQuoteMemoryRepository()
+	if err := a.initRepositories(); err != nil {
+		return err
+	}
 
-	// Initialize services
-	a.ChallengeService = service.NewChallengeService(
-		a.ChallengeRepository,
-		a.Config.Secret,
-		a.Config.Complexity,
-		a.Config.ExpirationTime,
+	// Initialize the adaptive complexity controller
+	a.ComplexityController = difficulty.NewController(
+		a.Config.MinComplexity,
+		a.Config.MaxComplexity,
+		complexityRetargetWindow,
+		complexityTargetRate,
 	)
+
+	// Initialize services
+	keyring := domain.Keyring{Keys: a.Config.Secrets, ActiveID: a.Config.ActiveKeyID}
+	verifier := domain.NewChallengeVerifier(keyring)
+	if a.Config.StatelessChallenges {
+		a.ChallengeService = service.NewStatelessChallengeService(
+			a.ReplayCache,
+			verifier,
+			a.ComplexityController,
+			a.Config.ExpirationTime,
+			a.enabledProviders(verifier.Clock),
+			a.Config.DefaultAlgorithm,
+			a.Config.MaxAttempts,
+		)
+	} else {
+		a.ChallengeService = service.NewChallengeService(
+			a.ChallengeRepository,
+			verifier,
+			a.ComplexityController,
+			a.Config.ExpirationTime,
+			a.enabledProviders(verifier.Clock),
+			a.Config.DefaultAlgorithm,
+			a.Config.MaxAttempts,
+		)
+	}
 	a.QuoteService = service.NewQuoteService(a.QuoteRepository)
 
 	// Initialize server
-	a.Server = tcp.NewServer(a.ChallengeService, a.QuoteService)
+	a.Server = tcp.NewServer(a.ChallengeService, a.QuoteService, a.ComplexityController, a.Config.MaxConnections)
 
 	kit.Logger.Info("application initialized",
 		"address", a.Config.Address,
-		"complexity", a.Config.Complexity,
+		"min_complexity", a.Config.MinComplexity,
+		"max_complexity", a.Config.MaxComplexity,
 		"expiration_time", a.Config.ExpirationTime,
+		"storage_driver", a.Config.StorageDriver,
 	)
 
 	return nil
 }
 
+// initRepositories constructs QuoteRepository for Config.StorageDriver, and
+// either ChallengeRepository or ReplayCache depending on
+// Config.StatelessChallenges. "bolt" uses separate BoltDB files for
+// challenges and quotes, derived from Config.BoltPath, so either store can
+// be inspected or backed up independently
+func (a *App) initRepositories() error {
+	switch a.Config.StorageDriver {
+	case repository.DriverBolt:
+		quoteRepository, err := repository.NewQuoteBoltRepository(a.Config.BoltPath + ".quotes")
+		if err != nil {
+			return err
+		}
+		a.QuoteRepository = quoteRepository
+
+		if a.Config.StatelessChallenges {
+			a.ReplayCache = repository.NewReplayCacheMemory()
+			return nil
+		}
+
+		challengeRepository, err := repository.NewChallengeBoltRepository(a.Config.BoltPath + ".challenges")
+		if err != nil {
+			return err
+		}
+		a.ChallengeRepository = challengeRepository
+	default:
+		a.QuoteRepository = repository.NewQuoteMemoryRepository()
+
+		if a.Config.StatelessChallenges {
+			a.ReplayCache = repository.NewReplayCacheMemory()
+			return nil
+		}
+
+		a.ChallengeRepository = repository.NewChallengeMemoryRepository()
+	}
+	return nil
+}
+
+// enabledProviders builds the ChallengeProvider registry handed to the
+// challenge service, restricted to Config.EnabledProviders when set. clock
+// is threaded through to every provider so they share the verifier's notion
+// of time
+func (a *App) enabledProviders(clock domain.Clock) map[string]domain.ChallengeProvider {
+	all := pow.DefaultProviders(clock)
+	if len(a.Config.EnabledProviders) == 0 {
+		return all
+	}
+
+	enabled := make(map[string]domain.ChallengeProvider, len(a.Config.EnabledProviders))
+	for _, name := range a.Config.EnabledProviders {
+		if provider, ok := all[name]; ok {
+			enabled[name] = provider
+		}
+	}
+	return enabled
+}
+
 // Start starts the application
 func (a *App) Start() error {
 	// Add some default quotes if repository is empty
@@ -88,13 +264,45 @@ func (a *App) Start() error {
 	}
 
 	kit.Logger.Info("starting server", "address", a.Config.Address)
-	return a.Server.Start(a.Config.Address)
+	return a.Server.Start(context.Background(), a.Config.Address)
 }
 
 // Stop gracefully stops the application
 func (a *App) Stop() error {
 	kit.Logger.Info("stopping server")
-	return a.Server.Stop()
+	if err := a.Server.Stop(); err != nil {
+		return err
+	}
+
+	// Repositories that own background resources (e.g. a sweeper goroutine
+	// or an open BoltDB file) implement io.Closer; others don't need to be
+	// stopped explicitly
+	if closer, ok := a.ChallengeRepository.(io.Closer); ok {
+		kit.Logger.Info("stopping challenge repository")
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	if closer, ok := a.QuoteRepository.(io.Closer); ok {
+		kit.Logger.Info("stopping quote repository")
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	if closer, ok := a.ReplayCache.(io.Closer); ok {
+		kit.Logger.Info("stopping replay cache")
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	if closer, ok := a.ComplexityController.(io.Closer); ok {
+		kit.Logger.Info("stopping complexity controller")
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // addDefaultQuotes adds some default quotes to the repository
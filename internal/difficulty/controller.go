@@ -0,0 +1,208 @@
+// Package difficulty implements a default domain.AdaptiveComplexityController
+// that retargets PoW complexity based on recent server load, with per-client
+// penalties for repeat offenders.
+package difficulty
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"word-of-wisdom/internal/domain"
+)
+
+// event is a single recorded outcome, timestamped so it can be dropped once
+// it falls outside the controller's sliding window
+type event struct {
+	at      time.Time
+	outcome domain.Outcome
+}
+
+// bucketSweepInterval and bucketIdleTTL bound how long a client's token
+// bucket lingers in Controller.buckets after its last activity. Without
+// this, an attacker (or just churn in client addresses over the server's
+// lifetime) would grow that map without bound, the same leak
+// NewChallengeMemoryRepositoryWithSweep guards against for challenges
+const (
+	bucketSweepInterval = time.Minute
+	bucketIdleTTL       = 10 * time.Minute
+)
+
+// Controller is a sliding-window AdaptiveComplexityController. It tracks
+// solved/failed/rejected outcomes over window, and retargets Current()
+// multiplicatively (like Bitcoin's difficulty adjustment) whenever the
+// observed load diverges far enough from targetRate
+type Controller struct {
+	mu sync.Mutex
+
+	min, max *big.Int
+	current  *big.Int
+
+	window     time.Duration
+	targetRate float64
+	events     []event
+
+	buckets            map[string]*tokenBucket
+	bucketCapacity     float64
+	bucketRefillPerSec float64
+
+	stopSweep chan struct{}
+	closeOnce sync.Once
+}
+
+// NewController creates a Controller clamped to [min, max], retargeting
+// against a sliding window of the given duration. targetRate is the number
+// of solved+rejected outcomes per second the controller aims to hold steady;
+// above it complexity rises, below it complexity falls. A background
+// goroutine evicts token buckets idle for longer than bucketIdleTTL; callers
+// must call Close to stop it
+func NewController(min, max *big.Int, window time.Duration, targetRate float64) *Controller {
+	c := &Controller{
+		min:                new(big.Int).Set(min),
+		max:                new(big.Int).Set(max),
+		current:            new(big.Int).Set(min),
+		window:             window,
+		targetRate:         targetRate,
+		buckets:            make(map[string]*tokenBucket),
+		bucketCapacity:     10,
+		bucketRefillPerSec: 1,
+		stopSweep:          make(chan struct{}),
+	}
+	go c.sweepLoop(bucketSweepInterval)
+	return c
+}
+
+// sweepLoop periodically evicts idle token buckets until Close is called
+func (c *Controller) sweepLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.sweepBuckets()
+		case <-c.stopSweep:
+			return
+		}
+	}
+}
+
+// sweepBuckets removes every token bucket that hasn't recorded an outcome
+// in at least bucketIdleTTL
+func (c *Controller) sweepBuckets() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cutoff := time.Now().Add(-bucketIdleTTL)
+	for addr, b := range c.buckets {
+		if b.last.Before(cutoff) {
+			delete(c.buckets, addr)
+		}
+	}
+}
+
+// Close stops the background bucket sweeper goroutine. Safe to call
+// multiple times
+func (c *Controller) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.stopSweep)
+	})
+	return nil
+}
+
+// Current returns the controller's current baseline complexity
+func (c *Controller) Current() *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.current)
+}
+
+// Complexity returns the baseline complexity scaled up for clientAddr if its
+// token bucket shows a history of failed or rejected outcomes
+func (c *Controller) Complexity(clientAddr string) *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.retarget(now)
+
+	target := new(big.Int).Set(c.current)
+	scale := c.bucketFor(clientAddr).penaltyScale(now)
+	if scale > 1 {
+		target.Mul(target, big.NewInt(scale))
+	}
+	return target
+}
+
+// RecordOutcome feeds a single outcome into both the sliding window used for
+// retargeting and clientAddr's token bucket used for per-client scaling
+func (c *Controller) RecordOutcome(clientAddr string, outcome domain.Outcome) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.events = append(c.events, event{at: now, outcome: outcome})
+	c.retarget(now)
+	c.bucketFor(clientAddr).record(outcome, now)
+}
+
+// retarget drops events outside the window and, when the observed load
+// diverges far enough from targetRate, multiplicatively adjusts current,
+// clamped to [min, max]. Must be called with mu held
+func (c *Controller) retarget(now time.Time) {
+	cutoff := now.Add(-c.window)
+	drop := 0
+	for drop < len(c.events) && c.events[drop].at.Before(cutoff) {
+		drop++
+	}
+	c.events = c.events[drop:]
+
+	var load float64
+	for _, e := range c.events {
+		switch e.outcome {
+		case domain.OutcomeSolved, domain.OutcomeRejected:
+			load++
+		}
+	}
+	load /= c.window.Seconds()
+
+	if c.targetRate <= 0 {
+		return
+	}
+	ratio := load / c.targetRate
+
+	switch {
+	case ratio > 1.25:
+		c.scale(5, 4) // load running hot: step complexity up 25%
+	case ratio < 0.75:
+		c.scale(4, 5) // load running cool: step complexity down 20%
+	}
+
+	if c.current.Cmp(c.min) < 0 {
+		c.current.Set(c.min)
+	}
+	if c.current.Cmp(c.max) > 0 {
+		c.current.Set(c.max)
+	}
+}
+
+// scale multiplies current by numerator/denominator, rounding towards 1
+// rather than 0 so it can never get stuck at zero
+func (c *Controller) scale(numerator, denominator int64) {
+	c.current.Mul(c.current, big.NewInt(numerator))
+	c.current.Div(c.current, big.NewInt(denominator))
+	if c.current.Sign() == 0 {
+		c.current.SetInt64(1)
+	}
+}
+
+// bucketFor returns clientAddr's token bucket, creating it on first use.
+// Must be called with mu held
+func (c *Controller) bucketFor(clientAddr string) *tokenBucket {
+	b, ok := c.buckets[clientAddr]
+	if !ok {
+		b = newTokenBucket(c.bucketCapacity, c.bucketRefillPerSec)
+		c.buckets[clientAddr] = b
+	}
+	return b
+}
@@ -0,0 +1,77 @@
+package difficulty
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"word-of-wisdom/internal/domain"
+)
+
+func TestController_StartsAtMin(t *testing.T) {
+	c := NewController(big.NewInt(10), big.NewInt(1000), time.Second, 5)
+	assert.Equal(t, int64(10), c.Current().Int64())
+}
+
+func TestController_BurstyTrafficRaisesComplexity(t *testing.T) {
+	c := NewController(big.NewInt(10), big.NewInt(1000), 50*time.Millisecond, 5)
+
+	// Flood far more outcomes than targetRate within the window
+	for i := 0; i < 50; i++ {
+		c.RecordOutcome("1.2.3.4", domain.OutcomeSolved)
+	}
+
+	assert.True(t, c.Current().Int64() > 10, "complexity should rise under bursty load")
+}
+
+func TestController_SteadyTrafficStaysNearMin(t *testing.T) {
+	c := NewController(big.NewInt(10), big.NewInt(1000), 200*time.Millisecond, 100)
+
+	// A handful of outcomes well under targetRate shouldn't trigger a raise
+	for i := 0; i < 3; i++ {
+		c.RecordOutcome("5.6.7.8", domain.OutcomeSolved)
+	}
+
+	assert.Equal(t, int64(10), c.Current().Int64())
+}
+
+func TestController_ComplexityNeverExceedsMax(t *testing.T) {
+	c := NewController(big.NewInt(10), big.NewInt(20), 50*time.Millisecond, 1)
+
+	for i := 0; i < 200; i++ {
+		c.RecordOutcome("9.9.9.9", domain.OutcomeSolved)
+	}
+
+	assert.True(t, c.Current().Int64() <= 20)
+}
+
+func TestController_RepeatOffenderGetsHigherComplexity(t *testing.T) {
+	c := NewController(big.NewInt(10), big.NewInt(1000), time.Minute, 1000)
+
+	clean := c.Complexity("clean-client")
+
+	for i := 0; i < 10; i++ {
+		c.RecordOutcome("bad-client", domain.OutcomeFailed)
+	}
+	offender := c.Complexity("bad-client")
+
+	assert.True(t, offender.Cmp(clean) > 0, "a client with a history of failures should see higher complexity")
+}
+
+func TestController_EventsOutsideWindowAreDropped(t *testing.T) {
+	c := NewController(big.NewInt(10), big.NewInt(1000), 20*time.Millisecond, 5)
+
+	for i := 0; i < 50; i++ {
+		c.RecordOutcome("1.2.3.4", domain.OutcomeSolved)
+	}
+	raised := c.Current()
+	assert.True(t, raised.Int64() > 10)
+
+	time.Sleep(100 * time.Millisecond)
+	// Complexity only reads and retargets; it doesn't add a new event, so
+	// once the burst above has aged out of the window the load drops to zero
+	c.Complexity("probe")
+	assert.True(t, c.Current().Int64() < raised.Int64(), "complexity should fall back once the burst leaves the window")
+}
@@ -0,0 +1,70 @@
+package difficulty
+
+import (
+	"time"
+
+	"word-of-wisdom/internal/domain"
+)
+
+// tokenBucket tracks a single client's recent behavior: every failed or
+// rejected outcome consumes a token, and tokens refill steadily over time.
+// A bucket that's run dry flags a repeat offender
+type tokenBucket struct {
+	tokens   float64
+	capacity float64
+	refill   float64 // tokens regained per second
+	last     time.Time
+}
+
+func newTokenBucket(capacity, refillPerSec float64) *tokenBucket {
+	return &tokenBucket{
+		tokens:   capacity,
+		capacity: capacity,
+		refill:   refillPerSec,
+		last:     time.Now(),
+	}
+}
+
+// record refills the bucket for elapsed time, then consumes one token for
+// anything other than a solved outcome
+func (b *tokenBucket) record(outcome domain.Outcome, now time.Time) {
+	b.refillAt(now)
+	if outcome == domain.OutcomeSolved {
+		return
+	}
+	if b.tokens > 0 {
+		b.tokens--
+	}
+}
+
+func (b *tokenBucket) refillAt(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens += elapsed * b.refill
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+}
+
+// penaltyScale reports the complexity multiplier for the bucket's current
+// fill level: a full bucket scales 1x, a dry one scales up to 4x
+func (b *tokenBucket) penaltyScale(now time.Time) int64 {
+	b.refillAt(now)
+	if b.capacity == 0 {
+		return 1
+	}
+
+	switch fill := b.tokens / b.capacity; {
+	case fill > 0.75:
+		return 1
+	case fill > 0.5:
+		return 2
+	case fill > 0.25:
+		return 3
+	default:
+		return 4
+	}
+}
@@ -8,7 +8,9 @@ import (
 
 // Challenge represents a proof of work challenge sent by the server
 type Challenge struct {
-	// Complexity defines the required difficulty target for the solution hash
+	// Complexity is a leading-zero-bit count that controls how hard the
+	// solution hash is to find; see ChallengeParams.Complexity and
+	// ComplexityFromBits for the exact target this implies
 	Complexity *big.Int
 
 	// Nonce is the random value that needs to be used in hash calculation
@@ -24,17 +26,85 @@ type Challenge struct {
 	// Solution is the value that satisfies the proof of work requirement
 	// It is nil when the challenge is created and set when solved
 	Solution *big.Int
+
+	// Algorithm identifies the ChallengeProvider that generated and must
+	// verify this challenge, e.g. "sha256", "scrypt", "equihash" or
+	// "argon2id"
+	Algorithm string
+
+	// AlgorithmParams holds provider-specific parameters (e.g. scrypt's
+	// N/r/p) encoded in whatever form the owning provider understands.
+	// The core domain type treats it as opaque
+	AlgorithmParams []byte
+
+	// Status tracks this challenge's position in its RFC 8555-style
+	// validation state machine. Pending and Processing accept further
+	// Verify calls; Valid and Invalid are terminal
+	Status ChallengeStatus
+
+	// RetryAfter is the earliest time a client may call Verify again
+	// after a failed attempt, set using exponential backoff
+	RetryAfter time.Time
+
+	// Attempts counts the Verify calls whose solution was checked and
+	// rejected; a successful Verify, and a call turned away before
+	// RetryAfter, leave it unchanged
+	Attempts int
+
+	// MaxAttempts caps Attempts before the challenge moves to Invalid
+	MaxAttempts int
+
+	// Version is an optimistic-concurrency counter incremented by
+	// ChallengeRepository.UpdateChallenge. It is repository bookkeeping,
+	// not challenge state, so it is not covered by Signature
+	Version int
+}
+
+// ChallengeStatus models the validation state machine a Challenge moves
+// through, following the RFC 8555 ACME challenge states
+type ChallengeStatus string
+
+const (
+	// StatusPending is the initial state: no Verify attempt has been made yet
+	StatusPending ChallengeStatus = "pending"
+
+	// StatusProcessing means at least one Verify attempt failed but
+	// Attempts has not yet reached MaxAttempts; the client may retry
+	// after RetryAfter
+	StatusProcessing ChallengeStatus = "processing"
+
+	// StatusValid is terminal: the challenge was solved successfully
+	StatusValid ChallengeStatus = "valid"
+
+	// StatusInvalid is terminal: MaxAttempts failed attempts were made
+	StatusInvalid ChallengeStatus = "invalid"
+)
+
+// IsTerminal reports whether status accepts no further Verify attempts
+func (s ChallengeStatus) IsTerminal() bool {
+	return s == StatusValid || s == StatusInvalid
 }
 
 // ChallengeService defines operations for managing proof-of-work challenges
 type ChallengeService interface {
-	// Generate creates a new challenge
+	// Generate creates a new challenge using the named algorithm, or the
+	// service's configured default algorithm when algorithm is empty.
+	// clientAddr identifies the requesting client (e.g. its remote address)
+	// and is handed to the AdaptiveComplexityController so repeat offenders
+	// can be served a higher complexity
 	// Returns the signed challenge or error if generation fails
-	Generate(ctx context.Context) (*Challenge, error)
+	Generate(ctx context.Context, algorithm string, clientAddr string) (*Challenge, error)
 
-	// Verify checks if the provided solution matches the challenge
+	// Verify checks if the provided solution matches the challenge.
+	// challengeID identifies the challenge being verified: the
+	// repository-backed implementation expects the bare 32-byte
+	// Challenge.ID(), while StatelessChallengeService expects the full
+	// ChallengeToken Generate returned, since it has nowhere else to recover
+	// the challenge's state from. clientAddr identifies the requesting
+	// client and is used to feed the AdaptiveComplexityController's
+	// per-client tracking
 	// Returns nil if solution is valid, otherwise returns error
-	Verify(ctx context.Context, challengeID []byte, solution *big.Int) error
+	Verify(ctx context.Context, challengeID []byte, solution *big.Int, clientAddr string) error
 
 	// Solve attempts to find a solution for the given challenge
 	// Returns the solution or error if solving fails
@@ -51,7 +121,24 @@ type ChallengeRepository interface {
 	// Returns ErrNotFound if challenge doesn't exist
 	GetChallenge(ctx context.Context, id []byte) (*Challenge, error)
 
+	// UpdateChallenge persists changes to an existing challenge using
+	// optimistic locking on Challenge.Version
+	// Returns ErrConflict if challenge.Version doesn't match the stored version
+	UpdateChallenge(ctx context.Context, challenge *Challenge) error
+
 	// DeleteChallenge removes a challenge by its ID
 	// Returns error if deletion fails
 	DeleteChallenge(ctx context.Context, id []byte) error
 }
+
+// ReplayCache lets a stateless ChallengeService (which keeps no
+// per-challenge record of its own) still refuse to honor the same solved
+// ChallengeToken twice. Implementations only need to remember an id until
+// its expiresAt has passed, so it can be backed by something far lighter
+// than a full ChallengeRepository
+type ReplayCache interface {
+	// MarkRedeemed records id as redeemed until expiresAt.
+	// Returns ErrAlreadyRedeemed if id was already marked and that earlier
+	// entry hasn't expired yet
+	MarkRedeemed(ctx context.Context, id []byte, expiresAt time.Time) error
+}
@@ -2,110 +2,123 @@ package domain
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/binary"
 	"math/big"
-	"time"
 )
 
-// ID generates a unique identifier for the challenge by hashing its fields
+// ID generates a unique identifier for the challenge by hashing its
+// canonical byte encoding (see canonicalBytes): complexity, nonce, expiry,
+// algorithm and algorithm params. The signature is deliberately excluded,
+// since it's computed from the ID itself
 func (s *Challenge) ID() []byte {
-	// Create a buffer to hold all fields that contribute to the ID
-	buf := new(bytes.Buffer)
-
-	// Write complexity as bytes
-	complexityBytes := s.Complexity.Bytes()
-	_ = binary.Write(buf, binary.BigEndian, int64(len(complexityBytes)))
-	buf.Write(complexityBytes)
-
-	// Write nonce
-	_ = binary.Write(buf, binary.BigEndian, int64(len(s.Nonce)))
-	buf.Write(s.Nonce)
-
-	// Write expiration time as Unix timestamp
-	_ = binary.Write(buf, binary.BigEndian, s.ExpiresAt.Unix())
-
-	// Calculate SHA-256 hash of all fields
-	hash := sha256.Sum256(buf.Bytes())
+	hash := sha256.Sum256(s.canonicalBytes())
 	return hash[:]
 }
 
-// Sign calculates and sets the signature for the challenge using the provided secret
-// The signature is a SHA-256 hash of the challenge ID concatenated with the secret
-func (s *Challenge) Sign(secret []byte) {
-	// Create a buffer to hold ID and secret
+// canonicalBytes encodes the fields that identify a challenge into a stable
+// byte sequence suitable for hashing into ID. It does not need to be
+// parseable back into a Challenge: unlike domain.ChallengeToken (the actual
+// wire format handed to clients), this encoding never leaves the process
+func (s *Challenge) canonicalBytes() []byte {
 	buf := new(bytes.Buffer)
+	writeBytes := func(b []byte) {
+		_ = binary.Write(buf, binary.BigEndian, int64(len(b)))
+		buf.Write(b)
+	}
+	writeBytes(s.Complexity.Bytes())
+	writeBytes(s.Nonce)
+	_ = binary.Write(buf, binary.BigEndian, s.ExpiresAt.Unix())
+	writeBytes([]byte(s.Algorithm))
+	writeBytes(s.AlgorithmParams)
+	return buf.Bytes()
+}
 
-	// Write challenge ID
-	id := s.ID()
-	buf.Write(id)
+// signingPayload builds the bytes that get hashed (together with a secret)
+// to produce the challenge signature. Besides the immutable ID it also
+// covers the mutable state-machine fields (Status, Attempts, RetryAfter)
+// so a client cannot forge progress through the validation state machine
+func (s *Challenge) signingPayload() []byte {
+	buf := new(bytes.Buffer)
 
-	// Write secret
-	buf.Write(secret)
+	buf.Write(s.ID())
+	buf.WriteString(string(s.Status))
+	_ = binary.Write(buf, binary.BigEndian, int64(s.Attempts))
+	_ = binary.Write(buf, binary.BigEndian, s.RetryAfter.Unix())
 
-	// Calculate signature
-	signature := sha256.Sum256(buf.Bytes())
-	s.Signature = signature[:]
+	return buf.Bytes()
 }
 
-// VerifySignature checks if the challenge signature is valid using the provided secret
-// Returns true if signature is valid and challenge has not expired, false otherwise
-func (s *Challenge) VerifySignature(secret []byte) bool {
-	// Check if signature exists
-	if s.Signature == nil {
-		return false
+// Sign computes an HMAC-SHA256 of the signing payload under keyring's active
+// key and sets it as the challenge's signature, prefixed with that key's ID
+// so a future VerifySignature can find the right key again even after it
+// has been rotated out of ActiveID. Signing with an unknown ActiveID leaves
+// the challenge unsigned
+func (s *Challenge) Sign(keyring Keyring) {
+	key, ok := keyring.active()
+	if !ok {
+		return
 	}
 
-	// Check if challenge has expired
-	if time.Now().After(s.ExpiresAt) {
-		return false
-	}
+	mac := hmac.New(sha256.New, key.Key)
+	mac.Write(s.signingPayload())
+	s.Signature = encodeSignature(key.ID, mac.Sum(nil))
+}
 
-	// Create a buffer to hold ID and secret
+// encodeSignature prepends keyID (length-prefixed, as elsewhere in this
+// package) to mac, so VerifySignature can recover which key to check against
+func encodeSignature(keyID string, mac []byte) []byte {
 	buf := new(bytes.Buffer)
-
-	// Write challenge ID
-	id := s.ID()
-	buf.Write(id)
-
-	// Write secret
-	buf.Write(secret)
-
-	// Calculate expected signature
-	expectedSignature := sha256.Sum256(buf.Bytes())
-
-	// Compare with stored signature
-	return bytes.Equal(expectedSignature[:], s.Signature)
+	_ = binary.Write(buf, binary.BigEndian, int64(len(keyID)))
+	buf.WriteString(keyID)
+	buf.Write(mac)
+	return buf.Bytes()
 }
 
-// VerifySolution checks if the provided solution satisfies the proof-of-work requirement
-// The hash of (challenge_id + solution) must have enough leading zeros based on complexity
-func (s *Challenge) VerifySolution(solution *big.Int) bool {
-	// Check if challenge has expired
-	if time.Now().After(s.ExpiresAt) {
-		return false
+// decodeSignature splits a signature produced by encodeSignature back into
+// the key ID and MAC it carries
+func decodeSignature(signature []byte) (keyID string, mac []byte, ok bool) {
+	if len(signature) < 8 {
+		return "", nil, false
 	}
+	idLen := int64(binary.BigEndian.Uint64(signature[:8]))
+	rest := signature[8:]
+	if idLen < 0 || idLen > int64(len(rest)) {
+		return "", nil, false
+	}
+	return string(rest[:idLen]), rest[idLen:], true
+}
 
-	// Calculate hash of challenge ID and solution
-	hash := sha256.New()
-	hash.Write(s.ID())
-	hash.Write(solution.Bytes())
-	hashBytes := hash.Sum(nil)
-
-	// Convert complexity to number of leading zero bytes required
-	requiredZeros := s.Complexity.Int64()
-	if requiredZeros <= 0 {
-		return false
+// maxPoWTarget is the largest possible value a SHA-256 digest can take
+// (2^256 - 1), i.e. the target at zero required bits
+var maxPoWTarget = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// ComplexityFromBits converts a leading-zero-bit count into the target a
+// solution's hash must fall strictly below. n is clamped to [0, 256]: a
+// target of maxPoWTarget (n<=0) is satisfied by any hash, and a target of 0
+// (n>=256) is satisfiable by no hash
+func ComplexityFromBits(n int) *big.Int {
+	if n <= 0 {
+		return new(big.Int).Set(maxPoWTarget)
 	}
+	if n >= 256 {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Rsh(maxPoWTarget, uint(n))
+}
 
-	// Check leading zeros in the hash
-	for i := int64(0); i < requiredZeros; i++ {
-		if hashBytes[i] != 0 {
-			return false
-		}
+// ComplexityFromExpectedHashes converts a desired average number of hash
+// attempts per solution into the equivalent target, in the same form
+// ComplexityFromBits returns. Challenge.Complexity and the configured
+// complexity bounds are bit counts, so this target is not accepted in their
+// place. n <= 0 is treated as 1 (the easiest non-trivial target)
+func ComplexityFromExpectedHashes(n uint64) *big.Int {
+	if n <= 1 {
+		return new(big.Int).Set(maxPoWTarget)
 	}
-	return true
+	return new(big.Int).Div(maxPoWTarget, new(big.Int).SetUint64(n))
 }
 
 // GenerateNonce creates a cryptographically secure random nonce
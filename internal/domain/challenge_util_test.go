@@ -78,6 +78,44 @@ func TestChallenge_ID(t *testing.T) {
 		}
 		assert.True(t, bytes.Equal(baseChallenge.ID(), withSolution.ID()))
 	})
+
+	t.Run("should generate different ID for different algorithm", func(t *testing.T) {
+		differentAlgorithm := &Challenge{
+			Complexity: baseChallenge.Complexity,
+			Nonce:      baseChallenge.Nonce,
+			ExpiresAt:  baseChallenge.ExpiresAt,
+			Algorithm:  "scrypt",
+		}
+		assert.False(t, bytes.Equal(baseChallenge.ID(), differentAlgorithm.ID()))
+	})
+
+	t.Run("should generate different ID for different algorithm params", func(t *testing.T) {
+		differentParams := &Challenge{
+			Complexity:      baseChallenge.Complexity,
+			Nonce:           baseChallenge.Nonce,
+			ExpiresAt:       baseChallenge.ExpiresAt,
+			AlgorithmParams: []byte{0x01, 0x02, 0x03},
+		}
+		assert.False(t, bytes.Equal(baseChallenge.ID(), differentParams.ID()))
+	})
+}
+
+func TestChallengeStatus_IsTerminal(t *testing.T) {
+	t.Run("pending is not terminal", func(t *testing.T) {
+		assert.False(t, StatusPending.IsTerminal())
+	})
+
+	t.Run("processing is not terminal", func(t *testing.T) {
+		assert.False(t, StatusProcessing.IsTerminal())
+	})
+
+	t.Run("valid is terminal", func(t *testing.T) {
+		assert.True(t, StatusValid.IsTerminal())
+	})
+
+	t.Run("invalid is terminal", func(t *testing.T) {
+		assert.True(t, StatusInvalid.IsTerminal())
+	})
 }
 
 func TestChallenge_Sign(t *testing.T) {
@@ -87,12 +125,15 @@ func TestChallenge_Sign(t *testing.T) {
 		Nonce:      []byte("test nonce"),
 		ExpiresAt:  baseTime,
 	}
-	secret := []byte("test secret")
+	secret := NewStaticKeyring([]byte("test secret"))
 
-	t.Run("should generate 32-byte signature", func(t *testing.T) {
+	t.Run("should embed a 32-byte HMAC-SHA256 MAC", func(t *testing.T) {
 		challenge.Sign(secret)
 		assert.NotNil(t, challenge.Signature)
-		assert.Len(t, challenge.Signature, 32, "Signature should be 32 bytes (SHA-256)")
+
+		_, mac, ok := decodeSignature(challenge.Signature)
+		assert.True(t, ok)
+		assert.Len(t, mac, 32, "MAC should be 32 bytes (HMAC-SHA256)")
 	})
 
 	t.Run("should generate same signature for same inputs", func(t *testing.T) {
@@ -124,8 +165,8 @@ func TestChallenge_Sign(t *testing.T) {
 			ExpiresAt:  baseTime,
 		}
 
-		challenge1.Sign([]byte("secret1"))
-		challenge2.Sign([]byte("secret2"))
+		challenge1.Sign(NewStaticKeyring([]byte("secret1")))
+		challenge2.Sign(NewStaticKeyring([]byte("secret2")))
 		assert.False(t, bytes.Equal(challenge1.Signature, challenge2.Signature))
 	})
 
@@ -147,9 +188,16 @@ func TestChallenge_Sign(t *testing.T) {
 	})
 }
 
-func TestChallenge_VerifySignature(t *testing.T) {
-	baseTime := time.Now().Add(time.Hour) // Future time
-	secret := []byte("test secret")
+// newTestVerifier creates a ChallengeVerifier backed by a FakeClock fixed at
+// now, so expiry checks in these tests are deterministic
+func newTestVerifier(keyring Keyring, now time.Time) *ChallengeVerifier {
+	return &ChallengeVerifier{Keyring: keyring, Clock: NewFakeClock(now)}
+}
+
+func TestChallengeVerifier_VerifySignature(t *testing.T) {
+	fixedNow := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	baseTime := fixedNow.Add(time.Hour) // Future relative to fixedNow
+	secret := NewStaticKeyring([]byte("test secret"))
 
 	t.Run("should verify valid non-expired challenge", func(t *testing.T) {
 		challenge := &Challenge{
@@ -158,17 +206,17 @@ func TestChallenge_VerifySignature(t *testing.T) {
 			ExpiresAt:  baseTime,
 		}
 		challenge.Sign(secret)
-		assert.True(t, challenge.VerifySignature(secret))
+		assert.True(t, newTestVerifier(secret, fixedNow).VerifySignature(challenge))
 	})
 
 	t.Run("should fail with expired challenge", func(t *testing.T) {
 		challenge := &Challenge{
 			Complexity: big.NewInt(1000),
 			Nonce:      []byte("test nonce"),
-			ExpiresAt:  time.Now().Add(-time.Hour), // Past time
+			ExpiresAt:  fixedNow.Add(-time.Hour), // Past relative to fixedNow
 		}
 		challenge.Sign(secret)
-		assert.False(t, challenge.VerifySignature(secret))
+		assert.False(t, newTestVerifier(secret, fixedNow).VerifySignature(challenge))
 	})
 
 	t.Run("should fail with nil signature", func(t *testing.T) {
@@ -177,7 +225,7 @@ func TestChallenge_VerifySignature(t *testing.T) {
 			Nonce:      []byte("test nonce"),
 			ExpiresAt:  baseTime,
 		}
-		assert.False(t, challenge.VerifySignature(secret))
+		assert.False(t, newTestVerifier(secret, fixedNow).VerifySignature(challenge))
 	})
 
 	t.Run("should fail with wrong secret", func(t *testing.T) {
@@ -187,8 +235,8 @@ func TestChallenge_VerifySignature(t *testing.T) {
 			ExpiresAt:  baseTime,
 		}
 		challenge.Sign(secret)
-		wrongSecret := []byte("wrong secret")
-		assert.False(t, challenge.VerifySignature(wrongSecret))
+		wrongSecret := NewStaticKeyring([]byte("wrong secret"))
+		assert.False(t, newTestVerifier(wrongSecret, fixedNow).VerifySignature(challenge))
 	})
 
 	t.Run("should fail with modified complexity", func(t *testing.T) {
@@ -199,7 +247,7 @@ func TestChallenge_VerifySignature(t *testing.T) {
 		}
 		challenge.Sign(secret)
 		challenge.Complexity = big.NewInt(500) // Modify after signing
-		assert.False(t, challenge.VerifySignature(secret))
+		assert.False(t, newTestVerifier(secret, fixedNow).VerifySignature(challenge))
 	})
 
 	t.Run("should fail with modified nonce", func(t *testing.T) {
@@ -210,7 +258,7 @@ func TestChallenge_VerifySignature(t *testing.T) {
 		}
 		challenge.Sign(secret)
 		challenge.Nonce = []byte("modified nonce") // Modify after signing
-		assert.False(t, challenge.VerifySignature(secret))
+		assert.False(t, newTestVerifier(secret, fixedNow).VerifySignature(challenge))
 	})
 
 	t.Run("should fail with modified expiration", func(t *testing.T) {
@@ -221,16 +269,84 @@ func TestChallenge_VerifySignature(t *testing.T) {
 		}
 		challenge.Sign(secret)
 		challenge.ExpiresAt = baseTime.Add(time.Hour) // Modify after signing
-		assert.False(t, challenge.VerifySignature(secret))
+		assert.False(t, newTestVerifier(secret, fixedNow).VerifySignature(challenge))
+	})
+
+	t.Run("should fail with modified status", func(t *testing.T) {
+		challenge := &Challenge{
+			Complexity: big.NewInt(1000),
+			Nonce:      []byte("test nonce"),
+			ExpiresAt:  baseTime,
+			Status:     StatusPending,
+		}
+		challenge.Sign(secret)
+		challenge.Status = StatusInvalid // Modify after signing
+		assert.False(t, newTestVerifier(secret, fixedNow).VerifySignature(challenge))
+	})
+
+	t.Run("should fail with modified attempts", func(t *testing.T) {
+		challenge := &Challenge{
+			Complexity: big.NewInt(1000),
+			Nonce:      []byte("test nonce"),
+			ExpiresAt:  baseTime,
+			Attempts:   1,
+		}
+		challenge.Sign(secret)
+		challenge.Attempts = 2 // Modify after signing
+		assert.False(t, newTestVerifier(secret, fixedNow).VerifySignature(challenge))
+	})
+
+	t.Run("should fail with modified retry-after", func(t *testing.T) {
+		challenge := &Challenge{
+			Complexity: big.NewInt(1000),
+			Nonce:      []byte("test nonce"),
+			ExpiresAt:  baseTime,
+			RetryAfter: baseTime.Add(-30 * time.Minute),
+		}
+		challenge.Sign(secret)
+		challenge.RetryAfter = baseTime // Modify after signing
+		assert.False(t, newTestVerifier(secret, fixedNow).VerifySignature(challenge))
+	})
+
+	t.Run("should verify a signature from a key rotated out of ActiveID", func(t *testing.T) {
+		oldKey := SecretKey{ID: "k1", Key: []byte("old key")}
+		newKey := SecretKey{ID: "k2", Key: []byte("new key")}
+
+		challenge := &Challenge{
+			Complexity: big.NewInt(1000),
+			Nonce:      []byte("test nonce"),
+			ExpiresAt:  baseTime,
+		}
+		challenge.Sign(Keyring{Keys: []SecretKey{oldKey}, ActiveID: oldKey.ID})
+
+		rotated := Keyring{Keys: []SecretKey{oldKey, newKey}, ActiveID: newKey.ID}
+		assert.True(t, newTestVerifier(rotated, fixedNow).VerifySignature(challenge))
+	})
+
+	t.Run("should reject a signature from a key no longer in the keyring", func(t *testing.T) {
+		oldKey := SecretKey{ID: "k1", Key: []byte("old key")}
+		newKey := SecretKey{ID: "k2", Key: []byte("new key")}
+
+		challenge := &Challenge{
+			Complexity: big.NewInt(1000),
+			Nonce:      []byte("test nonce"),
+			ExpiresAt:  baseTime,
+		}
+		challenge.Sign(Keyring{Keys: []SecretKey{oldKey}, ActiveID: oldKey.ID})
+
+		retired := Keyring{Keys: []SecretKey{newKey}, ActiveID: newKey.ID}
+		assert.False(t, newTestVerifier(retired, fixedNow).VerifySignature(challenge))
 	})
 }
 
-func TestChallenge_VerifySolution(t *testing.T) {
-	baseTime := time.Now().Add(time.Hour) // Future time
+func TestChallengeVerifier_VerifySolution(t *testing.T) {
+	fixedNow := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	baseTime := fixedNow.Add(time.Hour) // Future relative to fixedNow
+	verifier := newTestVerifier(Keyring{}, fixedNow)
 
-	t.Run("should verify valid solution with 1 leading zero", func(t *testing.T) {
+	t.Run("should verify valid solution with 1 leading zero bit", func(t *testing.T) {
 		challenge := &Challenge{
-			Complexity: big.NewInt(1), // Require 1 leading zero byte
+			Complexity: big.NewInt(1), // Require 1 leading zero bit
 			Nonce:      []byte("test nonce"),
 			ExpiresAt:  baseTime,
 		}
@@ -239,23 +355,23 @@ func TestChallenge_VerifySolution(t *testing.T) {
 		solution := big.NewInt(0)
 		found := false
 		for i := 0; i < 100000 && !found; i++ {
-			if challenge.VerifySolution(solution) {
+			if verifier.VerifySolution(challenge, solution) {
 				found = true
 				break
 			}
 			solution.Add(solution, big.NewInt(1))
 		}
-		assert.True(t, found, "Should find valid solution with 1 leading zero")
+		assert.True(t, found, "Should find valid solution with 1 leading zero bit")
 	})
 
 	t.Run("should fail with expired challenge", func(t *testing.T) {
 		challenge := &Challenge{
 			Complexity: big.NewInt(1),
 			Nonce:      []byte("test nonce"),
-			ExpiresAt:  time.Now().Add(-time.Hour), // Past time
+			ExpiresAt:  fixedNow.Add(-time.Hour), // Past relative to fixedNow
 		}
 		solution := big.NewInt(42)
-		assert.False(t, challenge.VerifySolution(solution))
+		assert.False(t, verifier.VerifySolution(challenge, solution))
 	})
 
 	t.Run("should fail with zero complexity", func(t *testing.T) {
@@ -265,7 +381,7 @@ func TestChallenge_VerifySolution(t *testing.T) {
 			ExpiresAt:  baseTime,
 		}
 		solution := big.NewInt(42)
-		assert.False(t, challenge.VerifySolution(solution))
+		assert.False(t, verifier.VerifySolution(challenge, solution))
 	})
 
 	t.Run("should fail with negative complexity", func(t *testing.T) {
@@ -275,7 +391,7 @@ func TestChallenge_VerifySolution(t *testing.T) {
 			ExpiresAt:  baseTime,
 		}
 		solution := big.NewInt(42)
-		assert.False(t, challenge.VerifySolution(solution))
+		assert.False(t, verifier.VerifySolution(challenge, solution))
 	})
 
 	t.Run("should be deterministic for same inputs", func(t *testing.T) {
@@ -285,14 +401,14 @@ func TestChallenge_VerifySolution(t *testing.T) {
 			ExpiresAt:  baseTime,
 		}
 		solution := big.NewInt(42)
-		result1 := challenge.VerifySolution(solution)
-		result2 := challenge.VerifySolution(solution)
+		result1 := verifier.VerifySolution(challenge, solution)
+		result2 := verifier.VerifySolution(challenge, solution)
 		assert.Equal(t, result1, result2)
 	})
 
-	t.Run("should verify solution with 2 leading zeros when required", func(t *testing.T) {
+	t.Run("should verify solution with 2 leading zero bits when required", func(t *testing.T) {
 		challenge := &Challenge{
-			Complexity: big.NewInt(2), // Require 2 leading zero bytes
+			Complexity: big.NewInt(2), // Require 2 leading zero bits
 			Nonce:      []byte("test nonce"),
 			ExpiresAt:  baseTime,
 		}
@@ -301,12 +417,60 @@ func TestChallenge_VerifySolution(t *testing.T) {
 		solution := big.NewInt(0)
 		found := false
 		for i := 0; i < 200000 && !found; i++ { // More iterations since it's harder
-			if challenge.VerifySolution(solution) {
+			if verifier.VerifySolution(challenge, solution) {
 				found = true
 				break
 			}
 			solution.Add(solution, big.NewInt(1))
 		}
-		assert.True(t, found, "Should find valid solution with 2 leading zeros")
+		assert.True(t, found, "Should find valid solution with 2 leading zero bits")
+	})
+}
+
+func TestFakeClock(t *testing.T) {
+	fixedNow := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Now returns the set instant", func(t *testing.T) {
+		clock := NewFakeClock(fixedNow)
+		assert.True(t, clock.Now().Equal(fixedNow))
+	})
+
+	t.Run("Advance moves the clock forward", func(t *testing.T) {
+		clock := NewFakeClock(fixedNow)
+		clock.Advance(time.Hour)
+		assert.True(t, clock.Now().Equal(fixedNow.Add(time.Hour)))
+	})
+
+	t.Run("Set moves the clock to an absolute instant", func(t *testing.T) {
+		clock := NewFakeClock(fixedNow)
+		clock.Set(fixedNow.Add(24 * time.Hour))
+		assert.True(t, clock.Now().Equal(fixedNow.Add(24*time.Hour)))
+	})
+}
+
+func TestComplexityFromBits(t *testing.T) {
+	t.Run("0 bits is satisfied by any hash", func(t *testing.T) {
+		assert.Equal(t, 0, ComplexityFromBits(0).Cmp(maxPoWTarget))
+	})
+
+	t.Run("256 bits is satisfiable by no hash", func(t *testing.T) {
+		assert.Equal(t, 0, ComplexityFromBits(256).Sign())
+	})
+
+	t.Run("each additional bit halves the target", func(t *testing.T) {
+		halved := new(big.Int).Rsh(ComplexityFromBits(10), 1)
+		assert.Equal(t, 0, halved.Cmp(ComplexityFromBits(11)))
+	})
+}
+
+func TestComplexityFromExpectedHashes(t *testing.T) {
+	t.Run("1 expected hash is satisfied by any hash", func(t *testing.T) {
+		assert.Equal(t, 0, ComplexityFromExpectedHashes(1).Cmp(maxPoWTarget))
+	})
+
+	t.Run("doubling the expected hashes roughly halves the target", func(t *testing.T) {
+		small := ComplexityFromExpectedHashes(1000)
+		large := ComplexityFromExpectedHashes(2000)
+		assert.True(t, large.Cmp(small) < 0)
 	})
 }
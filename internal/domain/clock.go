@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Clock abstracts the wall clock so expiry checks in this package can be
+// driven by a fixed instant in tests instead of depending on time.Now,
+// which makes those tests deterministic and immune to slow-CI flakiness
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock is the production Clock: Now delegates straight to time.Now
+type RealClock struct{}
+
+// Now returns the current wall-clock time
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock is a Clock for deterministic tests: Now returns whatever
+// instant it was last set or advanced to, and never moves on its own
+type FakeClock struct {
+	now time.Time
+}
+
+// NewFakeClock creates a FakeClock fixed at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the clock's current instant
+func (c *FakeClock) Now() time.Time {
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to the given instant
+func (c *FakeClock) Set(now time.Time) {
+	c.now = now
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "math/big"
+
+// Outcome records what happened to a single challenge from a given client,
+// so an AdaptiveComplexityController can factor it into its next retarget
+type Outcome int
+
+const (
+	// OutcomeSolved means the client submitted a valid solution
+	OutcomeSolved Outcome = iota
+
+	// OutcomeFailed means the client submitted an invalid solution
+	OutcomeFailed
+
+	// OutcomeRejected means the server declined to serve the client at all,
+	// e.g. because it was already under heavy load
+	OutcomeRejected
+)
+
+// AdaptiveComplexityController decides the PoW complexity handed to
+// ChallengeService.Generate. Unlike a static complexity value, it tracks
+// recent server load and retargets up or down within configured bounds,
+// similar in spirit to Bitcoin's difficulty retargeting but over a much
+// shorter (seconds) window
+type AdaptiveComplexityController interface {
+	// Complexity returns the target complexity to use for a new challenge
+	// requested by clientAddr. Implementations may scale it above the
+	// current baseline for addresses they've flagged as repeat offenders
+	Complexity(clientAddr string) *big.Int
+
+	// RecordOutcome feeds a single challenge outcome for clientAddr into the
+	// controller so it can factor it into its next retarget
+	RecordOutcome(clientAddr string, outcome Outcome)
+
+	// Current returns the controller's current baseline complexity, before
+	// any per-client scaling. Safe to call from a metrics endpoint
+	Current() *big.Int
+}
@@ -0,0 +1,41 @@
+package domain
+
+// SecretKey is one entry in a signing Keyring: an identifier and the raw
+// HMAC key bytes. Key rotation works by adding a new SecretKey under a new
+// ID, pointing Keyring.ActiveID at it, and only removing the old SecretKey
+// once every token signed with it has expired
+type SecretKey struct {
+	ID  string
+	Key []byte
+}
+
+// Keyring is the set of secrets Challenge.Sign and
+// ChallengeVerifier.VerifySignature use. Sign always signs with the ActiveID key; VerifySignature looks up
+// whichever key the signature itself names, so a challenge signed before a
+// rotation still verifies as long as its key remains in Keys
+type Keyring struct {
+	Keys     []SecretKey
+	ActiveID string
+}
+
+// NewStaticKeyring builds a single-key Keyring for deployments that don't
+// need rotation
+func NewStaticKeyring(key []byte) Keyring {
+	const staticKeyID = "default"
+	return Keyring{Keys: []SecretKey{{ID: staticKeyID, Key: key}}, ActiveID: staticKeyID}
+}
+
+// active returns the key ActiveID names
+func (k Keyring) active() (SecretKey, bool) {
+	return k.find(k.ActiveID)
+}
+
+// find returns the key with the given ID
+func (k Keyring) find(id string) (SecretKey, bool) {
+	for _, key := range k.Keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return SecretKey{}, false
+}
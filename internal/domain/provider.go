@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"math/big"
+	"time"
+)
+
+// ChallengeParams carries the tunable parameters a ChallengeProvider needs
+// in order to produce a new Challenge.
+type ChallengeParams struct {
+	// Complexity is a leading-zero-bit count: sha256, scrypt and argon2id
+	// require their digest, read as a big-endian big.Int, to fall below
+	// ComplexityFromBits(Complexity); equihash requires that many leading
+	// bits to collide between its two candidate hashes. It holds the bit
+	// count itself: the targets ComplexityFromBits and
+	// ComplexityFromExpectedHashes return are not valid Complexity values
+	Complexity *big.Int
+
+	// ExpiresIn controls how long the generated challenge remains valid
+	ExpiresIn time.Duration
+}
+
+// ChallengeProvider implements a single proof-of-work algorithm behind a
+// common interface so the service layer can generate, verify and solve
+// challenges without knowing which algorithm is in use.
+type ChallengeProvider interface {
+	// Type returns the algorithm identifier this provider handles, e.g.
+	// "sha256". It is stored on Challenge.Algorithm
+	Type() string
+
+	// Generate produces a new, unsigned challenge for this algorithm.
+	// The caller is responsible for signing and persisting the result
+	Generate(ctx context.Context, params ChallengeParams) (*Challenge, error)
+
+	// Verify reports whether solution satisfies challenge under this
+	// algorithm's rules
+	Verify(challenge *Challenge, solution *big.Int) bool
+
+	// Solve searches for a solution that satisfies challenge
+	Solve(ctx context.Context, challenge *Challenge) (*big.Int, error)
+}
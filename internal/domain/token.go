@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"time"
+)
+
+// noRetryAfterSentinel marks an encoded RetryAfter as the zero time.Time: no
+// real Unix timestamp will ever collide with math.MinInt64
+const noRetryAfterSentinel = int64(math.MinInt64)
+
+// ChallengeToken is the opaque, self-contained encoding of a Challenge that
+// the server hands to the client in place of keeping the challenge in
+// server-side storage. It carries every field VerifySignature and
+// VerifySolution need to reach a decision — including the mutable
+// validation-state-machine fields covered by Signature — so a
+// ChallengeService can verify a solution from the token alone
+type ChallengeToken []byte
+
+// Marshal encodes the challenge into a ChallengeToken. Signature must already be set
+// via Sign: an unsigned challenge can't be trusted back from a client, so
+// there is no point handing one out as a token
+func (s *Challenge) Marshal() (ChallengeToken, error) {
+	if len(s.Signature) == 0 {
+		return nil, fmt.Errorf("cannot marshal an unsigned challenge")
+	}
+
+	buf := new(bytes.Buffer)
+	writeBytes := func(b []byte) {
+		_ = binary.Write(buf, binary.BigEndian, int64(len(b)))
+		buf.Write(b)
+	}
+
+	writeBytes(s.Complexity.Bytes())
+	writeBytes(s.Nonce)
+	_ = binary.Write(buf, binary.BigEndian, s.ExpiresAt.Unix())
+	writeBytes([]byte(s.Algorithm))
+	writeBytes(s.AlgorithmParams)
+	writeBytes([]byte(s.Status))
+
+	retryAfter := noRetryAfterSentinel
+	if !s.RetryAfter.IsZero() {
+		retryAfter = s.RetryAfter.Unix()
+	}
+	_ = binary.Write(buf, binary.BigEndian, retryAfter)
+
+	_ = binary.Write(buf, binary.BigEndian, int64(s.Attempts))
+	_ = binary.Write(buf, binary.BigEndian, int64(s.MaxAttempts))
+	writeBytes(s.Signature)
+
+	return ChallengeToken(buf.Bytes()), nil
+}
+
+// DecodeChallengeToken decodes a ChallengeToken back into a Challenge. It
+// does not check the signature — callers must call VerifySignature on the
+// result before trusting any of its fields
+func DecodeChallengeToken(token ChallengeToken) (*Challenge, error) {
+	buf := bytes.NewReader(token)
+
+	readBytes := func(field string) ([]byte, error) {
+		var n int64
+		if err := binary.Read(buf, binary.BigEndian, &n); err != nil {
+			return nil, fmt.Errorf("read %s length: %w", field, err)
+		}
+		if n < 0 || n > int64(buf.Len()) {
+			return nil, fmt.Errorf("invalid %s length %d", field, n)
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(buf, b); err != nil {
+			return nil, fmt.Errorf("read %s: %w", field, err)
+		}
+		return b, nil
+	}
+	readInt64 := func(field string) (int64, error) {
+		var n int64
+		if err := binary.Read(buf, binary.BigEndian, &n); err != nil {
+			return 0, fmt.Errorf("read %s: %w", field, err)
+		}
+		return n, nil
+	}
+
+	complexityBytes, err := readBytes("complexity")
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := readBytes("nonce")
+	if err != nil {
+		return nil, err
+	}
+	expiresAtUnix, err := readInt64("expires_at")
+	if err != nil {
+		return nil, err
+	}
+	algorithm, err := readBytes("algorithm")
+	if err != nil {
+		return nil, err
+	}
+	algorithmParams, err := readBytes("algorithm_params")
+	if err != nil {
+		return nil, err
+	}
+	status, err := readBytes("status")
+	if err != nil {
+		return nil, err
+	}
+	retryAfterUnix, err := readInt64("retry_after")
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := readInt64("attempts")
+	if err != nil {
+		return nil, err
+	}
+	maxAttempts, err := readInt64("max_attempts")
+	if err != nil {
+		return nil, err
+	}
+	signature, err := readBytes("signature")
+	if err != nil {
+		return nil, err
+	}
+
+	retryAfter := time.Time{}
+	if retryAfterUnix != noRetryAfterSentinel {
+		retryAfter = time.Unix(retryAfterUnix, 0)
+	}
+
+	return &Challenge{
+		Complexity:      new(big.Int).SetBytes(complexityBytes),
+		Nonce:           nonce,
+		ExpiresAt:       time.Unix(expiresAtUnix, 0),
+		Signature:       signature,
+		Algorithm:       string(algorithm),
+		AlgorithmParams: algorithmParams,
+		Status:          ChallengeStatus(status),
+		RetryAfter:      retryAfter,
+		Attempts:        int(attempts),
+		MaxAttempts:     int(maxAttempts),
+	}, nil
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"math/big"
+)
+
+// ChallengeVerifier checks a Challenge's signature and proof-of-work
+// solution against a Keyring and a Clock. Bundling the clock here, instead
+// of each check calling time.Now() directly, lets tests drive expiry with a
+// fixed FakeClock rather than the wall clock. Construct with
+// NewChallengeVerifier for production use; set Clock to a *FakeClock in tests
+type ChallengeVerifier struct {
+	Keyring Keyring
+	Clock   Clock
+}
+
+// NewChallengeVerifier creates a ChallengeVerifier backed by RealClock
+func NewChallengeVerifier(keyring Keyring) *ChallengeVerifier {
+	return &ChallengeVerifier{Keyring: keyring, Clock: RealClock{}}
+}
+
+// VerifySignature checks challenge's signature against v.Keyring, looking
+// up whichever key the signature names rather than assuming the keyring's
+// currently active one. Returns true if the signature is valid under that
+// key and the challenge has not expired according to v.Clock, false otherwise
+func (v *ChallengeVerifier) VerifySignature(challenge *Challenge) bool {
+	if challenge.Signature == nil {
+		return false
+	}
+
+	if v.Clock.Now().After(challenge.ExpiresAt) {
+		return false
+	}
+
+	keyID, mac, ok := decodeSignature(challenge.Signature)
+	if !ok {
+		return false
+	}
+
+	key, ok := v.Keyring.find(keyID)
+	if !ok {
+		return false
+	}
+
+	expectedMAC := hmac.New(sha256.New, key.Key)
+	expectedMAC.Write(challenge.signingPayload())
+
+	return hmac.Equal(expectedMAC.Sum(nil), mac)
+}
+
+// VerifySolution checks if the provided solution satisfies challenge's
+// proof-of-work requirement: SHA256(challenge_id || solution), read as a
+// big-endian big.Int, must be strictly less than the target derived from
+// Complexity (the Hashcash/Bitcoin convention). See ComplexityFromBits.
+// Expiry is checked against v.Clock rather than the wall clock
+func (v *ChallengeVerifier) VerifySolution(challenge *Challenge, solution *big.Int) bool {
+	if v.Clock.Now().After(challenge.ExpiresAt) {
+		return false
+	}
+
+	bits := challenge.Complexity.Int64()
+	if bits <= 0 {
+		return false
+	}
+
+	hash := sha256.New()
+	hash.Write(challenge.ID())
+	hash.Write(solution.Bytes())
+	hashInt := new(big.Int).SetBytes(hash.Sum(nil))
+
+	return hashInt.Cmp(ComplexityFromBits(int(bits))) < 0
+}
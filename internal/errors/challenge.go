@@ -1,6 +1,10 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // Challenge-related errors
 var (
@@ -11,4 +15,32 @@ var (
 	ErrNoSolutionFound   = errors.New("no solution found")
 	ErrInvalidChallenge  = errors.New("invalid challenge")
 	ErrSolutionNotFound  = errors.New("solution not found")
+	ErrUnknownAlgorithm  = errors.New("unknown challenge algorithm")
+	ErrChallengeTerminal = errors.New("challenge is already in a terminal state")
+	ErrConflict          = errors.New("challenge was updated concurrently")
+	ErrAlreadyRedeemed   = errors.New("challenge solution was already redeemed")
 )
+
+// RetryError is returned by ChallengeService.Verify when a solution attempt
+// fails but the challenge is still retryable. It carries a Retry-After hint
+// so the transport layer can surface it to the client
+type RetryError struct {
+	// Attempts is the number of rejected solutions recorded for the challenge
+	// so far, including this call's when its solution was checked; a call
+	// turned away before RetryAfter reports the count unchanged
+	Attempts int
+
+	// RetryAfter is the earliest time the client should try again
+	RetryAfter time.Time
+
+	// Err is the underlying reason this attempt failed
+	Err error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%s: retry after %s (attempt %d)", e.Err, e.RetryAfter.Format(time.RFC3339), e.Attempts)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
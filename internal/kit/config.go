@@ -0,0 +1,77 @@
+package kit
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Log sink names accepted by Config.Sinks
+const (
+	SinkStderr = "stderr"
+	SinkSyslog = "syslog"
+	SinkFile   = "file"
+)
+
+// Log encoding formats accepted by Config.Format
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Config selects InitLogger's backends. Sinks may be combined freely, e.g.
+// []string{SinkStderr, SinkFile} logs every line to both stderr and a
+// rotating file
+type Config struct {
+	// Format is FormatJSON (the default) or FormatConsole, the
+	// human-friendly encoding meant for local development
+	Format string
+
+	// Sinks lists which backends receive every log line. Defaults to
+	// []string{SinkStderr} when empty
+	Sinks []string
+
+	// SyslogAddr is "network://address" (e.g. "udp://localhost:514") for a
+	// remote syslog daemon, or empty to dial the local syslog socket.
+	// Only consulted when Sinks includes SinkSyslog
+	SyslogAddr string
+
+	// LogFile is the rotating log file path. Only consulted when Sinks
+	// includes SinkFile
+	LogFile string
+}
+
+// InitLogger rebuilds the package-level Logger from cfg, combining one
+// zapcore.Core per requested sink with zapcore.NewTee so every log line is
+// written to all of them
+func InitLogger(cfg Config) error {
+	sinks := cfg.Sinks
+	if len(sinks) == 0 {
+		sinks = []string{SinkStderr}
+	}
+
+	cores := make([]zapcore.Core, 0, len(sinks))
+	for _, sink := range sinks {
+		switch sink {
+		case SinkStderr:
+			cores = append(cores, stderrCore(cfg.Format))
+		case SinkFile:
+			if cfg.LogFile == "" {
+				return fmt.Errorf("log sink %q requires LogFile to be set", SinkFile)
+			}
+			cores = append(cores, fileCore(cfg.Format, cfg.LogFile))
+		case SinkSyslog:
+			core, err := syslogCore(cfg.Format, cfg.SyslogAddr)
+			if err != nil {
+				return err
+			}
+			cores = append(cores, core)
+		default:
+			return fmt.Errorf("unknown log sink %q", sink)
+		}
+	}
+
+	Logger = &sugaredLogger{zap.New(zapcore.NewTee(cores...)).Sugar()}
+	return nil
+}
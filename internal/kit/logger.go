@@ -6,10 +6,53 @@ import (
 	"go.uber.org/zap"
 )
 
-var (
-	once   sync.Once
-	Logger *zap.SugaredLogger
-)
+// StructuredLogger is the logging interface used throughout the module. It
+// mirrors the subset of zap.SugaredLogger's API call sites rely on, so they
+// read exactly as they did back when Logger was a concrete
+// *zap.SugaredLogger
+type StructuredLogger interface {
+	// Info logs args at info level, concatenating them the way fmt.Sprint
+	// does when there's no separator between operands
+	Info(args ...interface{})
+
+	// Infow logs a message at info level alongside structured key/value pairs
+	Infow(msg string, keysAndValues ...interface{})
+
+	// Error logs args at error level, concatenating them like Info
+	Error(args ...interface{})
+
+	// Errorw logs a message at error level alongside structured key/value pairs
+	Errorw(msg string, keysAndValues ...interface{})
+
+	// Fatal logs args at fatal level, concatenating them like Info, then
+	// calls os.Exit(1)
+	Fatal(args ...interface{})
+
+	// With returns a StructuredLogger that prepends keysAndValues to every
+	// subsequent call, letting callers correlate a run of log lines (e.g.
+	// every line for one TCP connection) without repeating the fields
+	// themselves
+	With(keysAndValues ...interface{}) StructuredLogger
+}
+
+// sugaredLogger adapts a *zap.SugaredLogger to StructuredLogger; every
+// method is a direct delegation since SugaredLogger already exposes this
+// exact API
+type sugaredLogger struct {
+	*zap.SugaredLogger
+}
+
+func (s *sugaredLogger) With(keysAndValues ...interface{}) StructuredLogger {
+	return &sugaredLogger{s.SugaredLogger.With(keysAndValues...)}
+}
+
+var once sync.Once
+
+// Logger is the package-level logger used everywhere in the module. It
+// starts out as a zap production logger so packages that log before
+// InitLogger runs (or in tests, which never call it) still get usable
+// JSON-to-stderr output. Call InitLogger to reconfigure it from a Config
+var Logger StructuredLogger
 
 func init() {
 	once.Do(func() {
@@ -17,7 +60,6 @@ func init() {
 		if err != nil {
 			panic(err)
 		}
-
-		Logger = l.Sugar()
+		Logger = &sugaredLogger{l.Sugar()}
 	})
 }
@@ -0,0 +1,61 @@
+package kit
+
+import (
+	"fmt"
+	"log/syslog"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// newEncoder builds the zapcore.Encoder for format: FormatConsole is a
+// human-friendly development encoding, anything else (including the
+// default, empty string) is JSON
+func newEncoder(format string) zapcore.Encoder {
+	if format == FormatConsole {
+		return zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	}
+	return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+}
+
+// stderrCore logs to stderr, matching the single backend kit used before
+// this file existed
+func stderrCore(format string) zapcore.Core {
+	return zapcore.NewCore(newEncoder(format), zapcore.Lock(os.Stderr), zapcore.InfoLevel)
+}
+
+// fileCore logs to a size- and age-rotated file at path
+func fileCore(format, path string) zapcore.Core {
+	writer := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    100, // megabytes
+		MaxBackups: 5,
+		MaxAge:     28, // days
+		Compress:   true,
+	}
+	return zapcore.NewCore(newEncoder(format), zapcore.AddSync(writer), zapcore.InfoLevel)
+}
+
+// syslogCore logs to a syslog daemon. addr is "network://address" (e.g.
+// "udp://localhost:514") for a remote daemon, or empty to dial the local
+// syslog socket over the platform's default transport
+func syslogCore(format, addr string) (zapcore.Core, error) {
+	var network, raddr string
+	if addr != "" {
+		var ok bool
+		network, raddr, ok = strings.Cut(addr, "://")
+		if !ok {
+			return nil, fmt.Errorf("invalid syslog address %q: expected network://address", addr)
+		}
+	}
+
+	writer, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_DAEMON, "word-of-wisdom")
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial syslog: %w", err)
+	}
+
+	return zapcore.NewCore(newEncoder(format), zapcore.AddSync(writer), zapcore.InfoLevel), nil
+}
@@ -0,0 +1,123 @@
+package pow
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+
+	"word-of-wisdom/internal/domain"
+	"word-of-wisdom/internal/errors"
+)
+
+const AlgorithmArgon2id = "argon2id"
+
+// argon2idProvider is a memory-hard provider built on Argon2id: the
+// solution is valid when Argon2id(password=solution, salt=id, timeCost,
+// memory, parallelism), read as a big-endian big.Int, falls below the target
+// derived from Complexity leading zero bits (see domain.ComplexityFromBits).
+// Its tunable memory cost makes brute-forcing far more expensive to
+// parallelize on GPUs/ASICs than sha256Provider, and harder to shrink the
+// memory footprint of than scryptProvider
+type argon2idProvider struct {
+	timeCost, memory uint32
+	parallelism      uint8
+	keyLen           uint32
+	maxIterations    int64
+	clock            domain.Clock
+}
+
+// NewArgon2idProvider creates an Argon2id-backed provider with the given
+// cost parameters. Sensible defaults for an interactive challenge are
+// timeCost=1, memory=64*1024 (64MiB), parallelism=1. clock drives this
+// provider's expiry check, so a FakeClock installed on the service's
+// verifier reaches it too instead of each provider consulting time.Now independently
+func NewArgon2idProvider(clock domain.Clock, timeCost, memory uint32, parallelism uint8) domain.ChallengeProvider {
+	return &argon2idProvider{timeCost: timeCost, memory: memory, parallelism: parallelism, keyLen: 32, maxIterations: 20000, clock: clock}
+}
+
+func (pr *argon2idProvider) Type() string {
+	return AlgorithmArgon2id
+}
+
+func (pr *argon2idProvider) encodeParams() []byte {
+	buf := new(bytes.Buffer)
+	_ = binary.Write(buf, binary.BigEndian, pr.timeCost)
+	_ = binary.Write(buf, binary.BigEndian, pr.memory)
+	_ = binary.Write(buf, binary.BigEndian, pr.parallelism)
+	return buf.Bytes()
+}
+
+func decodeArgon2idParams(params []byte) (timeCost, memory uint32, parallelism uint8, ok bool) {
+	if len(params) != 9 {
+		return 0, 0, 0, false
+	}
+	timeCost = binary.BigEndian.Uint32(params[0:4])
+	memory = binary.BigEndian.Uint32(params[4:8])
+	parallelism = params[8]
+	return timeCost, memory, parallelism, true
+}
+
+func (pr *argon2idProvider) Generate(ctx context.Context, params domain.ChallengeParams) (*domain.Challenge, error) {
+	nonce, err := domain.GenerateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Challenge{
+		Algorithm:       AlgorithmArgon2id,
+		Complexity:      new(big.Int).Set(params.Complexity),
+		Nonce:           nonce,
+		ExpiresAt:       time.Now().Add(params.ExpiresIn),
+		AlgorithmParams: pr.encodeParams(),
+	}, nil
+}
+
+func (pr *argon2idProvider) derive(challenge *domain.Challenge, solution *big.Int) ([]byte, bool) {
+	timeCost, memory, parallelism, ok := decodeArgon2idParams(challenge.AlgorithmParams)
+	if !ok {
+		return nil, false
+	}
+
+	salt := challenge.ID()
+	password := solution.Bytes()
+	return argon2.IDKey(password, salt, timeCost, memory, parallelism, pr.keyLen), true
+}
+
+func (pr *argon2idProvider) Verify(challenge *domain.Challenge, solution *big.Int) bool {
+	if pr.clock.Now().After(challenge.ExpiresAt) {
+		return false
+	}
+
+	bits := challenge.Complexity.Int64()
+	if bits <= 0 {
+		return false
+	}
+
+	key, ok := pr.derive(challenge, solution)
+	if !ok {
+		return false
+	}
+
+	keyInt := new(big.Int).SetBytes(key)
+	return keyInt.Cmp(domain.ComplexityFromBits(int(bits))) < 0
+}
+
+func (pr *argon2idProvider) Solve(ctx context.Context, challenge *domain.Challenge) (*big.Int, error) {
+	solution := big.NewInt(0)
+	for i := int64(0); i < pr.maxIterations; i++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			if pr.Verify(challenge, solution) {
+				return solution, nil
+			}
+			solution.Add(solution, big.NewInt(1))
+		}
+	}
+	return nil, errors.ErrSolutionNotFound
+}
@@ -0,0 +1,143 @@
+package pow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"time"
+
+	"word-of-wisdom/internal/domain"
+	"word-of-wisdom/internal/errors"
+)
+
+const AlgorithmEquihash = "equihash"
+
+// equihashProvider is a simplified, two-list (k=2) generalized-birthday
+// provider in the spirit of Equihash: a solution is a pair of distinct
+// indices (i, j) whose hashes collide on the leading Complexity bits. This
+// is not full Wagner's algorithm (no k>2 list merging), but it keeps the
+// same asymmetry property: finding a collision costs roughly 2^(bits/2)
+// hash evaluations, while verifying it costs exactly two
+type equihashProvider struct {
+	maxAttempts int
+	clock       domain.Clock
+}
+
+// NewEquihashProvider creates the simplified Equihash-style provider.
+// clock drives this provider's expiry check, so a FakeClock installed on
+// the service's verifier reaches it too instead of each provider
+// consulting time.Now independently
+func NewEquihashProvider(clock domain.Clock) domain.ChallengeProvider {
+	return &equihashProvider{maxAttempts: 1 << 20, clock: clock}
+}
+
+func (p *equihashProvider) Type() string {
+	return AlgorithmEquihash
+}
+
+func (p *equihashProvider) Generate(ctx context.Context, params domain.ChallengeParams) (*domain.Challenge, error) {
+	nonce, err := domain.GenerateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Challenge{
+		Algorithm:  AlgorithmEquihash,
+		Complexity: new(big.Int).Set(params.Complexity),
+		Nonce:      nonce,
+		ExpiresAt:  time.Now().Add(params.ExpiresIn),
+	}, nil
+}
+
+// indexHash hashes the challenge nonce together with a candidate index
+func indexHash(challenge *domain.Challenge, index uint32) [32]byte {
+	buf := make([]byte, len(challenge.Nonce)+4)
+	copy(buf, challenge.Nonce)
+	binary.BigEndian.PutUint32(buf[len(challenge.Nonce):], index)
+	return sha256.Sum256(buf)
+}
+
+// leadingBitsEqual reports whether a and b share their first n bits
+func leadingBitsEqual(a, b [32]byte, n int64) bool {
+	fullBytes := n / 8
+	for i := int64(0); i < fullBytes; i++ {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	remainder := uint(n % 8)
+	if remainder == 0 {
+		return true
+	}
+	mask := byte(0xFF << (8 - remainder))
+	return a[fullBytes]&mask == b[fullBytes]&mask
+}
+
+// packIndices encodes two 32-bit indices into a single big.Int solution
+func packIndices(i, j uint32) *big.Int {
+	packed := uint64(i)<<32 | uint64(j)
+	return new(big.Int).SetUint64(packed)
+}
+
+func unpackIndices(solution *big.Int) (i, j uint32) {
+	packed := solution.Uint64()
+	return uint32(packed >> 32), uint32(packed)
+}
+
+func (p *equihashProvider) Verify(challenge *domain.Challenge, solution *big.Int) bool {
+	if p.clock.Now().After(challenge.ExpiresAt) {
+		return false
+	}
+
+	bits := challenge.Complexity.Int64()
+	if bits <= 0 || bits > 256 {
+		return false
+	}
+
+	i, j := unpackIndices(solution)
+	if i == j {
+		return false
+	}
+
+	return leadingBitsEqual(indexHash(challenge, i), indexHash(challenge, j), bits)
+}
+
+func (p *equihashProvider) Solve(ctx context.Context, challenge *domain.Challenge) (*big.Int, error) {
+	bits := challenge.Complexity.Int64()
+	seen := make(map[string]uint32, p.maxAttempts)
+
+	for idx := uint32(0); idx < uint32(p.maxAttempts); idx++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		h := indexHash(challenge, idx)
+		prefix := truncateBits(h, bits)
+		if other, ok := seen[prefix]; ok && other != idx {
+			return packIndices(other, idx), nil
+		}
+		seen[prefix] = idx
+	}
+
+	return nil, errors.ErrSolutionNotFound
+}
+
+// truncateBits returns the leading n bits of h as a byte-string map key
+func truncateBits(h [32]byte, n int64) string {
+	fullBytes := n / 8
+	remainder := uint(n % 8)
+	end := fullBytes
+	if remainder != 0 {
+		end++
+	}
+	out := make([]byte, end)
+	copy(out, h[:end])
+	if remainder != 0 {
+		mask := byte(0xFF << (8 - remainder))
+		out[fullBytes] &= mask
+	}
+	return string(out)
+}
@@ -0,0 +1,191 @@
+package pow
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"word-of-wisdom/internal/domain"
+)
+
+func TestDefaultProviders(t *testing.T) {
+	providers := DefaultProviders(domain.RealClock{})
+
+	assert.Len(t, providers, 4)
+	assert.Contains(t, providers, AlgorithmSHA256)
+	assert.Contains(t, providers, AlgorithmScrypt)
+	assert.Contains(t, providers, AlgorithmEquihash)
+	assert.Contains(t, providers, AlgorithmArgon2id)
+
+	for name, provider := range providers {
+		assert.Equal(t, name, provider.Type())
+	}
+}
+
+func TestSHA256Provider_RoundTrip(t *testing.T) {
+	provider := NewSHA256Provider(domain.RealClock{})
+	ctx := context.Background()
+
+	challenge, err := provider.Generate(ctx, domain.ChallengeParams{
+		Complexity: big.NewInt(1),
+		ExpiresIn:  time.Minute,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmSHA256, challenge.Algorithm)
+
+	solution, err := provider.Solve(ctx, challenge)
+	assert.NoError(t, err)
+	assert.True(t, provider.Verify(challenge, solution))
+}
+
+func TestScryptProvider_RoundTrip(t *testing.T) {
+	provider := NewScryptProvider(domain.RealClock{}, 16, 1, 1) // tiny cost parameters for a fast test
+	ctx := context.Background()
+
+	challenge, err := provider.Generate(ctx, domain.ChallengeParams{
+		Complexity: big.NewInt(1),
+		ExpiresIn:  time.Minute,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmScrypt, challenge.Algorithm)
+	assert.NotEmpty(t, challenge.AlgorithmParams)
+
+	solution, err := provider.Solve(ctx, challenge)
+	assert.NoError(t, err)
+	assert.True(t, provider.Verify(challenge, solution))
+}
+
+func TestEquihashProvider_RoundTrip(t *testing.T) {
+	provider := NewEquihashProvider(domain.RealClock{})
+	ctx := context.Background()
+
+	challenge, err := provider.Generate(ctx, domain.ChallengeParams{
+		Complexity: big.NewInt(8), // 8-bit prefix collision, cheap to find
+		ExpiresIn:  time.Minute,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmEquihash, challenge.Algorithm)
+
+	solution, err := provider.Solve(ctx, challenge)
+	assert.NoError(t, err)
+	assert.True(t, provider.Verify(challenge, solution))
+}
+
+func TestEquihashProvider_RejectsSameIndex(t *testing.T) {
+	provider := NewEquihashProvider(domain.RealClock{})
+	challenge := &domain.Challenge{
+		Algorithm:  AlgorithmEquihash,
+		Complexity: big.NewInt(8),
+		Nonce:      []byte("test nonce"),
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+
+	solution := packIndices(5, 5)
+	assert.False(t, provider.Verify(challenge, solution))
+}
+
+func TestArgon2idProvider_RoundTrip(t *testing.T) {
+	provider := NewArgon2idProvider(domain.RealClock{}, 1, 64, 1) // tiny memory cost for a fast test
+	ctx := context.Background()
+
+	challenge, err := provider.Generate(ctx, domain.ChallengeParams{
+		Complexity: big.NewInt(1),
+		ExpiresIn:  time.Minute,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmArgon2id, challenge.Algorithm)
+	assert.NotEmpty(t, challenge.AlgorithmParams)
+
+	solution, err := provider.Solve(ctx, challenge)
+	assert.NoError(t, err)
+	assert.True(t, provider.Verify(challenge, solution))
+}
+
+func TestArgon2idProvider_RejectsAlgorithmDowngrade(t *testing.T) {
+	// A solution valid under sha256's cheap hash must not verify against a
+	// challenge whose signature covered argon2id: AlgorithmParams are part
+	// of Challenge.ID(), so swapping providers after the fact changes the
+	// ID and invalidates the signature, not just this Verify call
+	provider := NewArgon2idProvider(domain.RealClock{}, 1, 64, 1)
+	challenge, err := provider.Generate(context.Background(), domain.ChallengeParams{
+		Complexity: big.NewInt(1),
+		ExpiresIn:  time.Minute,
+	})
+	assert.NoError(t, err)
+
+	downgraded := *challenge
+	downgraded.Algorithm = AlgorithmSHA256
+	downgraded.AlgorithmParams = nil
+
+	assert.NotEqual(t, challenge.ID(), downgraded.ID())
+}
+
+func BenchmarkSHA256Provider_Verify(b *testing.B) {
+	provider := NewSHA256Provider(domain.RealClock{})
+	challenge, err := provider.Generate(context.Background(), domain.ChallengeParams{
+		Complexity: big.NewInt(1),
+		ExpiresIn:  time.Minute,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	solution := big.NewInt(0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		provider.Verify(challenge, solution)
+	}
+}
+
+func BenchmarkArgon2idProvider_Verify(b *testing.B) {
+	provider := NewArgon2idProvider(domain.RealClock{}, 1, 64*1024, 1) // production-sized 64MiB cost
+	challenge, err := provider.Generate(context.Background(), domain.ChallengeParams{
+		Complexity: big.NewInt(1),
+		ExpiresIn:  time.Minute,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	solution := big.NewInt(0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		provider.Verify(challenge, solution)
+	}
+}
+
+// TestProviders_RespectInjectedClock confirms every built-in provider
+// checks expiry against its injected Clock rather than time.Now: advancing
+// a FakeClock past ExpiresAt must expire a challenge even though the wall
+// clock hasn't moved
+func TestProviders_RespectInjectedClock(t *testing.T) {
+	newProvider := map[string]func(domain.Clock) domain.ChallengeProvider{
+		AlgorithmSHA256:   func(c domain.Clock) domain.ChallengeProvider { return NewSHA256Provider(c) },
+		AlgorithmScrypt:   func(c domain.Clock) domain.ChallengeProvider { return NewScryptProvider(c, 16, 1, 1) },
+		AlgorithmEquihash: func(c domain.Clock) domain.ChallengeProvider { return NewEquihashProvider(c) },
+		AlgorithmArgon2id: func(c domain.Clock) domain.ChallengeProvider { return NewArgon2idProvider(c, 1, 64, 1) },
+	}
+
+	for name, construct := range newProvider {
+		t.Run(name, func(t *testing.T) {
+			clock := domain.NewFakeClock(time.Now())
+			provider := construct(clock)
+
+			challenge, err := provider.Generate(context.Background(), domain.ChallengeParams{
+				Complexity: big.NewInt(1),
+				ExpiresIn:  time.Minute,
+			})
+			assert.NoError(t, err)
+
+			solution, err := provider.Solve(context.Background(), challenge)
+			assert.NoError(t, err)
+			assert.True(t, provider.Verify(challenge, solution))
+
+			clock.Advance(2 * time.Minute)
+			assert.False(t, provider.Verify(challenge, solution))
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package pow
+
+import "word-of-wisdom/internal/domain"
+
+// DefaultProviders returns the built-in ChallengeProvider set keyed by
+// their Type(), ready to hand to service.NewChallengeService. clock is
+// threaded into every provider so they all check expiry against the same
+// clock as the rest of the service, e.g. a FakeClock in tests
+func DefaultProviders(clock domain.Clock) map[string]domain.ChallengeProvider {
+	providers := []domain.ChallengeProvider{
+		NewSHA256Provider(clock),
+		NewScryptProvider(clock, 16384, 8, 1),
+		NewEquihashProvider(clock),
+		NewArgon2idProvider(clock, 1, 64*1024, 1),
+	}
+
+	registry := make(map[string]domain.ChallengeProvider, len(providers))
+	for _, provider := range providers {
+		registry[provider.Type()] = provider
+	}
+	return registry
+}
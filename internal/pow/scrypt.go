@@ -0,0 +1,122 @@
+package pow
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+
+	"word-of-wisdom/internal/domain"
+	"word-of-wisdom/internal/errors"
+)
+
+const AlgorithmScrypt = "scrypt"
+
+// scryptProvider is a memory-hard provider: the solution is valid when
+// scrypt(password=solution, salt=id, N, r, p), read as a big-endian big.Int, falls
+// below the target derived from Complexity leading zero bits (see
+// domain.ComplexityFromBits). The memory cost makes it far more expensive
+// to accelerate on GPUs/ASICs than the plain SHA-256 provider
+type scryptProvider struct {
+	n, r, p       int
+	keyLen        int
+	maxIterations int64
+	clock         domain.Clock
+}
+
+// NewScryptProvider creates a scrypt-backed provider with the given cost
+// parameters. Sensible defaults for an interactive login-style challenge
+// are N=16384, r=8, p=1 (~16MiB of memory per attempt). clock drives this
+// provider's expiry check, so a FakeClock installed on the service's
+// verifier reaches it too instead of each provider consulting time.Now independently
+func NewScryptProvider(clock domain.Clock, n, r, p int) domain.ChallengeProvider {
+	return &scryptProvider{n: n, r: r, p: p, keyLen: 32, maxIterations: 20000, clock: clock}
+}
+
+func (pr *scryptProvider) Type() string {
+	return AlgorithmScrypt
+}
+
+func (pr *scryptProvider) encodeParams() []byte {
+	buf := new(bytes.Buffer)
+	_ = binary.Write(buf, binary.BigEndian, uint32(pr.n))
+	_ = binary.Write(buf, binary.BigEndian, uint32(pr.r))
+	_ = binary.Write(buf, binary.BigEndian, uint32(pr.p))
+	return buf.Bytes()
+}
+
+func decodeScryptParams(params []byte) (n, r, p int, ok bool) {
+	if len(params) != 12 {
+		return 0, 0, 0, false
+	}
+	n = int(binary.BigEndian.Uint32(params[0:4]))
+	r = int(binary.BigEndian.Uint32(params[4:8]))
+	p = int(binary.BigEndian.Uint32(params[8:12]))
+	return n, r, p, true
+}
+
+func (pr *scryptProvider) Generate(ctx context.Context, params domain.ChallengeParams) (*domain.Challenge, error) {
+	nonce, err := domain.GenerateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Challenge{
+		Algorithm:       AlgorithmScrypt,
+		Complexity:      new(big.Int).Set(params.Complexity),
+		Nonce:           nonce,
+		ExpiresAt:       time.Now().Add(params.ExpiresIn),
+		AlgorithmParams: pr.encodeParams(),
+	}, nil
+}
+
+func (pr *scryptProvider) derive(challenge *domain.Challenge, solution *big.Int) ([]byte, bool) {
+	n, r, p, ok := decodeScryptParams(challenge.AlgorithmParams)
+	if !ok {
+		return nil, false
+	}
+
+	key, err := scrypt.Key(solution.Bytes(), challenge.ID(), n, r, p, pr.keyLen)
+	if err != nil {
+		return nil, false
+	}
+	return key, true
+}
+
+func (pr *scryptProvider) Verify(challenge *domain.Challenge, solution *big.Int) bool {
+	if pr.clock.Now().After(challenge.ExpiresAt) {
+		return false
+	}
+
+	bits := challenge.Complexity.Int64()
+	if bits <= 0 {
+		return false
+	}
+
+	key, ok := pr.derive(challenge, solution)
+	if !ok {
+		return false
+	}
+
+	keyInt := new(big.Int).SetBytes(key)
+	return keyInt.Cmp(domain.ComplexityFromBits(int(bits))) < 0
+}
+
+func (pr *scryptProvider) Solve(ctx context.Context, challenge *domain.Challenge) (*big.Int, error) {
+	solution := big.NewInt(0)
+	for i := int64(0); i < pr.maxIterations; i++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			if pr.Verify(challenge, solution) {
+				return solution, nil
+			}
+			solution.Add(solution, big.NewInt(1))
+		}
+	}
+	return nil, errors.ErrSolutionNotFound
+}
@@ -0,0 +1,67 @@
+package pow
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"word-of-wisdom/internal/domain"
+	"word-of-wisdom/internal/errors"
+)
+
+const AlgorithmSHA256 = "sha256"
+
+// sha256Provider is the original Hashcash-style provider: the solution is
+// valid when SHA-256(id || solution), read as a big-endian big.Int, falls
+// below the target derived from Complexity leading zero bits (see
+// domain.ComplexityFromBits)
+type sha256Provider struct {
+	maxIterations int64
+	verifier      *domain.ChallengeVerifier
+}
+
+// NewSHA256Provider creates the default SHA-256 hashcash-style provider.
+// clock is threaded into the verifier it uses for Verify/Solve so expiry
+// checks follow the same clock as the rest of the service, rather than
+// each provider consulting the wall clock independently
+func NewSHA256Provider(clock domain.Clock) domain.ChallengeProvider {
+	return &sha256Provider{maxIterations: 1000000, verifier: &domain.ChallengeVerifier{Clock: clock}}
+}
+
+func (p *sha256Provider) Type() string {
+	return AlgorithmSHA256
+}
+
+func (p *sha256Provider) Generate(ctx context.Context, params domain.ChallengeParams) (*domain.Challenge, error) {
+	nonce, err := domain.GenerateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Challenge{
+		Algorithm:  AlgorithmSHA256,
+		Complexity: new(big.Int).Set(params.Complexity),
+		Nonce:      nonce,
+		ExpiresAt:  time.Now().Add(params.ExpiresIn),
+	}, nil
+}
+
+func (p *sha256Provider) Verify(challenge *domain.Challenge, solution *big.Int) bool {
+	return p.verifier.VerifySolution(challenge, solution)
+}
+
+func (p *sha256Provider) Solve(ctx context.Context, challenge *domain.Challenge) (*big.Int, error) {
+	solution := big.NewInt(0)
+	for i := int64(0); i < p.maxIterations; i++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			if p.verifier.VerifySolution(challenge, solution) {
+				return solution, nil
+			}
+			solution.Add(solution, big.NewInt(1))
+		}
+	}
+	return nil, errors.ErrSolutionNotFound
+}
@@ -1,35 +1,155 @@
 package repository
 
 import (
+	"container/heap"
 	"context"
 	"encoding/hex"
 	"math/big"
 	"sync"
+	"time"
 
 	"word-of-wisdom/internal/domain"
 	"word-of-wisdom/internal/errors"
 	"word-of-wisdom/internal/kit"
 )
 
+// defaultSweepInterval is how often NewChallengeMemoryRepository sweeps
+// expired challenges out of the map
+const defaultSweepInterval = 30 * time.Second
+
 // challengeMemoryRepository implements domain.ChallengeRepository interface with in-memory storage
 type challengeMemoryRepository struct {
 	sync.RWMutex
 	challenges map[string]*domain.Challenge // key is hex-encoded challenge ID
+	expiry     expiryHeap                   // min-heap of challenges by ExpiresAt, for O(k log n) sweeps
+
+	stopSweep chan struct{}
+	closeOnce sync.Once
+}
+
+// expiryEntry is a single challenge's position in expiryHeap. It is looked
+// up lazily against the challenges map on sweep, since a challenge may have
+// been deleted (or, in principle, re-created) after the entry was pushed
+type expiryEntry struct {
+	hexID     string
+	expiresAt time.Time
+}
+
+// expiryHeap orders expiryEntry values by soonest ExpiresAt first
+type expiryHeap []expiryEntry
+
+func (h expiryHeap) Len() int            { return len(h) }
+func (h expiryHeap) Less(i, j int) bool  { return h[i].expiresAt.Before(h[j].expiresAt) }
+func (h expiryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap) Push(x interface{}) { *h = append(*h, x.(expiryEntry)) }
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	*h = old[:n-1]
+	return entry
 }
 
 // NewChallengeMemoryRepository creates a new in-memory challenge repository
+// with a background sweeper that evicts expired challenges every 30 seconds
 func NewChallengeMemoryRepository() domain.ChallengeRepository {
-	kit.Logger.Info("initializing in-memory challenge repository")
-	return &challengeMemoryRepository{
+	return NewChallengeMemoryRepositoryWithSweep(defaultSweepInterval)
+}
+
+// NewChallengeMemoryRepositoryWithSweep creates a new in-memory challenge
+// repository whose background sweeper runs at the given interval. Callers
+// own the returned repository and must call Close to stop the sweeper
+func NewChallengeMemoryRepositoryWithSweep(interval time.Duration) domain.ChallengeRepository {
+	kit.Logger.Infow("initializing in-memory challenge repository",
+		"sweep_interval", interval)
+
+	r := &challengeMemoryRepository{
 		challenges: make(map[string]*domain.Challenge),
+		stopSweep:  make(chan struct{}),
+	}
+	go r.sweepLoop(interval)
+	return r
+}
+
+// sweepLoop periodically evicts expired challenges until Close is called
+func (r *challengeMemoryRepository) sweepLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.sweep()
+		case <-r.stopSweep:
+			return
+		}
 	}
 }
 
+// sweep removes every challenge whose ExpiresAt is in the past, walking the
+// expiry heap so the cost is O(k log n) in the number of expired entries
+// rather than O(n) over the whole map
+func (r *challengeMemoryRepository) sweep() {
+	r.Lock()
+	defer r.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for r.expiry.Len() > 0 && !r.expiry[0].expiresAt.After(now) {
+		entry := heap.Pop(&r.expiry).(expiryEntry)
+
+		// The entry may be stale (challenge deleted, or superseded by a
+		// later UpdateChallenge with a different ExpiresAt); only evict if
+		// it still matches what's stored
+		if challenge, exists := r.challenges[entry.hexID]; exists && !challenge.ExpiresAt.After(entry.expiresAt) {
+			delete(r.challenges, entry.hexID)
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		kit.Logger.Infow("swept expired challenges", "count", removed)
+	}
+}
+
+// Close stops the background sweeper goroutine. Safe to call multiple times
+func (r *challengeMemoryRepository) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.stopSweep)
+	})
+	return nil
+}
+
 // getHexID returns hex encoded ID for the challenge
 func getHexID(challenge *domain.Challenge) string {
 	return hex.EncodeToString(challenge.ID())
 }
 
+// cloneChallenge makes a deep copy of challenge so callers can't mutate
+// repository state through a returned pointer
+func cloneChallenge(challenge *domain.Challenge) *domain.Challenge {
+	clone := &domain.Challenge{
+		Complexity:      new(big.Int).Set(challenge.Complexity),
+		Nonce:           make([]byte, len(challenge.Nonce)),
+		ExpiresAt:       challenge.ExpiresAt,
+		Signature:       make([]byte, len(challenge.Signature)),
+		Algorithm:       challenge.Algorithm,
+		AlgorithmParams: make([]byte, len(challenge.AlgorithmParams)),
+		Status:          challenge.Status,
+		RetryAfter:      challenge.RetryAfter,
+		Attempts:        challenge.Attempts,
+		MaxAttempts:     challenge.MaxAttempts,
+		Version:         challenge.Version,
+	}
+	copy(clone.Nonce, challenge.Nonce)
+	copy(clone.Signature, challenge.Signature)
+	copy(clone.AlgorithmParams, challenge.AlgorithmParams)
+	if challenge.Solution != nil {
+		clone.Solution = new(big.Int).Set(challenge.Solution)
+	}
+	return clone
+}
+
 // CreateChallenge stores a new challenge
 func (r *challengeMemoryRepository) CreateChallenge(ctx context.Context, challenge *domain.Challenge) error {
 	r.Lock()
@@ -45,30 +165,24 @@ func (r *challengeMemoryRepository) CreateChallenge(ctx context.Context, challen
 		return errors.ErrChallengeExists
 	}
 
-	// Make a deep copy of the challenge to prevent external modifications
-	challengeCopy := &domain.Challenge{
-		Complexity: new(big.Int).Set(challenge.Complexity),
-		Nonce:      make([]byte, len(challenge.Nonce)),
-		ExpiresAt:  challenge.ExpiresAt,
-		Signature:  make([]byte, len(challenge.Signature)),
-	}
-	copy(challengeCopy.Nonce, challenge.Nonce)
-	copy(challengeCopy.Signature, challenge.Signature)
-	if challenge.Solution != nil {
-		challengeCopy.Solution = new(big.Int).Set(challenge.Solution)
-	}
+	challengeCopy := cloneChallenge(challenge)
+	challengeCopy.Version = 1
 
 	r.challenges[id] = challengeCopy
+	heap.Push(&r.expiry, expiryEntry{hexID: id, expiresAt: challengeCopy.ExpiresAt})
+
 	kit.Logger.Infow("created new challenge",
 		"id", id,
 		"expires_at", challenge.ExpiresAt)
 	return nil
 }
 
-// GetChallenge retrieves a challenge by its ID
+// GetChallenge retrieves a challenge by its ID. An entry whose ExpiresAt has
+// passed is evicted on the spot and reported as errors.ErrChallengeExpired
+// rather than returned, even if the background sweeper hasn't reached it yet
 func (r *challengeMemoryRepository) GetChallenge(ctx context.Context, id []byte) (*domain.Challenge, error) {
-	r.RLock()
-	defer r.RUnlock()
+	r.Lock()
+	defer r.Unlock()
 
 	hexID := hex.EncodeToString(id)
 	challenge, exists := r.challenges[hexID]
@@ -78,23 +192,54 @@ func (r *challengeMemoryRepository) GetChallenge(ctx context.Context, id []byte)
 		return nil, errors.ErrNotFound
 	}
 
-	// Return a copy to prevent external modifications
-	challengeCopy := &domain.Challenge{
-		Complexity: new(big.Int).Set(challenge.Complexity),
-		Nonce:      make([]byte, len(challenge.Nonce)),
-		ExpiresAt:  challenge.ExpiresAt,
-		Signature:  make([]byte, len(challenge.Signature)),
-	}
-	copy(challengeCopy.Nonce, challenge.Nonce)
-	copy(challengeCopy.Signature, challenge.Signature)
-	if challenge.Solution != nil {
-		challengeCopy.Solution = new(big.Int).Set(challenge.Solution)
+	if time.Now().After(challenge.ExpiresAt) {
+		delete(r.challenges, hexID)
+		kit.Logger.Errorw("failed to get challenge: expired",
+			"id", hexID,
+			"expires_at", challenge.ExpiresAt)
+		return nil, errors.ErrChallengeExpired
 	}
 
 	kit.Logger.Infow("retrieved challenge",
 		"id", hexID,
 		"expires_at", challenge.ExpiresAt)
-	return challengeCopy, nil
+	return cloneChallenge(challenge), nil
+}
+
+// UpdateChallenge persists changes to an existing challenge using
+// optimistic locking keyed on Version: callers must pass back the Version
+// they last read, and a concurrent update in between is reported as
+// errors.ErrConflict instead of silently being lost
+func (r *challengeMemoryRepository) UpdateChallenge(ctx context.Context, challenge *domain.Challenge) error {
+	r.Lock()
+	defer r.Unlock()
+
+	hexID := getHexID(challenge)
+	existing, exists := r.challenges[hexID]
+	if !exists {
+		kit.Logger.Errorw("failed to update challenge: not found",
+			"id", hexID)
+		return errors.ErrNotFound
+	}
+
+	if existing.Version != challenge.Version {
+		kit.Logger.Errorw("failed to update challenge: version conflict",
+			"id", hexID,
+			"expected_version", challenge.Version,
+			"actual_version", existing.Version)
+		return errors.ErrConflict
+	}
+
+	updated := cloneChallenge(challenge)
+	updated.Version = existing.Version + 1
+
+	r.challenges[hexID] = updated
+	kit.Logger.Infow("updated challenge",
+		"id", hexID,
+		"status", updated.Status,
+		"attempts", updated.Attempts,
+		"version", updated.Version)
+	return nil
 }
 
 // DeleteChallenge removes a challenge by its ID
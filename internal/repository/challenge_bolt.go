@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"word-of-wisdom/internal/domain"
+	"word-of-wisdom/internal/errors"
+	"word-of-wisdom/internal/kit"
+)
+
+// challengesBucket holds one entry per challenge, keyed by its hex-encoded ID
+var challengesBucket = []byte("challenges")
+
+// challengeBoltRepository implements domain.ChallengeRepository on top of an
+// embedded BoltDB file, so challenges survive a server restart
+type challengeBoltRepository struct {
+	db *bbolt.DB
+
+	stopSweep chan struct{}
+	closeOnce sync.Once
+}
+
+// NewChallengeBoltRepository opens (creating if necessary) a BoltDB file at
+// path and returns a ChallengeRepository backed by it, with a background
+// sweeper that purges expired challenges every defaultSweepInterval.
+// Callers own the returned repository and must call Close to release the
+// file and stop the sweeper
+func NewChallengeBoltRepository(path string) (domain.ChallengeRepository, error) {
+	return NewChallengeBoltRepositoryWithSweep(path, defaultSweepInterval)
+}
+
+// NewChallengeBoltRepositoryWithSweep is like NewChallengeBoltRepository but
+// lets the caller control the sweep interval, mirroring
+// NewChallengeMemoryRepositoryWithSweep
+func NewChallengeBoltRepositoryWithSweep(path string, interval time.Duration) (domain.ChallengeRepository, error) {
+	kit.Logger.Infow("initializing bolt challenge repository",
+		"path", path,
+		"sweep_interval", interval)
+
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(challengesBucket)
+		return err
+	}); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	r := &challengeBoltRepository{
+		db:        db,
+		stopSweep: make(chan struct{}),
+	}
+	go r.sweepLoop(interval)
+	return r, nil
+}
+
+// sweepLoop periodically purges expired challenges until Close is called
+func (r *challengeBoltRepository) sweepLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.sweep()
+		case <-r.stopSweep:
+			return
+		}
+	}
+}
+
+// sweep removes every challenge whose ExpiresAt is in the past, walking the
+// bucket with a cursor so expired entries can be deleted in place
+func (r *challengeBoltRepository) sweep() {
+	now := time.Now()
+	removed := 0
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(challengesBucket).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var challenge domain.Challenge
+			if err := json.Unmarshal(v, &challenge); err != nil {
+				continue
+			}
+			if !challenge.ExpiresAt.After(now) {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+				removed++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		kit.Logger.Errorw("failed to sweep expired challenges", "error", err)
+		return
+	}
+
+	if removed > 0 {
+		kit.Logger.Infow("swept expired challenges", "count", removed)
+	}
+}
+
+// Close stops the background sweeper and closes the underlying BoltDB file.
+// Safe to call multiple times
+func (r *challengeBoltRepository) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		close(r.stopSweep)
+		err = r.db.Close()
+	})
+	return err
+}
+
+// CreateChallenge stores a new challenge
+func (r *challengeBoltRepository) CreateChallenge(ctx context.Context, challenge *domain.Challenge) error {
+	id := getHexID(challenge)
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(challengesBucket)
+		if bucket.Get([]byte(id)) != nil {
+			return errors.ErrChallengeExists
+		}
+
+		stored := cloneChallenge(challenge)
+		stored.Version = 1
+
+		data, err := json.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(id), data)
+	})
+	if err != nil {
+		kit.Logger.Errorw("failed to create challenge", "id", id, "error", err)
+		return err
+	}
+
+	kit.Logger.Infow("created new challenge", "id", id, "expires_at", challenge.ExpiresAt)
+	return nil
+}
+
+// GetChallenge retrieves a challenge by its ID. An entry whose ExpiresAt has
+// passed is evicted on the spot and reported as errors.ErrChallengeExpired
+// rather than returned, even if the background sweeper hasn't reached it yet
+func (r *challengeBoltRepository) GetChallenge(ctx context.Context, id []byte) (*domain.Challenge, error) {
+	hexID := hex.EncodeToString(id)
+
+	var challenge *domain.Challenge
+	var expired bool
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(challengesBucket)
+		data := bucket.Get([]byte(hexID))
+		if data == nil {
+			return errors.ErrNotFound
+		}
+
+		var stored domain.Challenge
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return err
+		}
+
+		if time.Now().After(stored.ExpiresAt) {
+			expired = true
+			return bucket.Delete([]byte(hexID))
+		}
+
+		challenge = &stored
+		return nil
+	})
+	if err == nil && expired {
+		err = errors.ErrChallengeExpired
+	}
+	if err != nil {
+		kit.Logger.Errorw("failed to get challenge", "id", hexID, "error", err)
+		return nil, err
+	}
+
+	kit.Logger.Infow("retrieved challenge", "id", hexID, "expires_at", challenge.ExpiresAt)
+	return cloneChallenge(challenge), nil
+}
+
+// UpdateChallenge persists changes to an existing challenge using
+// optimistic locking keyed on Version, matching challengeMemoryRepository
+func (r *challengeBoltRepository) UpdateChallenge(ctx context.Context, challenge *domain.Challenge) error {
+	hexID := getHexID(challenge)
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(challengesBucket)
+		data := bucket.Get([]byte(hexID))
+		if data == nil {
+			return errors.ErrNotFound
+		}
+
+		var existing domain.Challenge
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return err
+		}
+		if existing.Version != challenge.Version {
+			return errors.ErrConflict
+		}
+
+		updated := cloneChallenge(challenge)
+		updated.Version = existing.Version + 1
+
+		encoded, err := json.Marshal(updated)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(hexID), encoded)
+	})
+	if err != nil {
+		kit.Logger.Errorw("failed to update challenge", "id", hexID, "error", err)
+		return err
+	}
+
+	kit.Logger.Infow("updated challenge", "id", hexID, "status", challenge.Status)
+	return nil
+}
+
+// DeleteChallenge removes a challenge by its ID
+func (r *challengeBoltRepository) DeleteChallenge(ctx context.Context, id []byte) error {
+	hexID := hex.EncodeToString(id)
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(challengesBucket)
+		if bucket.Get([]byte(hexID)) == nil {
+			return errors.ErrNotFound
+		}
+		return bucket.Delete([]byte(hexID))
+	})
+	if err != nil {
+		kit.Logger.Errorw("failed to delete challenge", "id", hexID, "error", err)
+		return err
+	}
+
+	kit.Logger.Infow("deleted challenge", "id", hexID)
+	return nil
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.etcd.io/bbolt"
+
+	"word-of-wisdom/internal/domain"
+	"word-of-wisdom/internal/errors"
+)
+
+type ChallengeBoltRepositoryTestSuite struct {
+	suite.Suite
+	repo domain.ChallengeRepository
+	ctx  context.Context
+}
+
+func (s *ChallengeBoltRepositoryTestSuite) SetupTest() {
+	repo, err := NewChallengeBoltRepository(filepath.Join(s.T().TempDir(), "challenges.db"))
+	require.NoError(s.T(), err)
+	s.repo = repo
+	s.ctx = context.Background()
+}
+
+func (s *ChallengeBoltRepositoryTestSuite) TearDownTest() {
+	assert.NoError(s.T(), s.repo.(io.Closer).Close())
+}
+
+func TestChallengeBoltRepository(t *testing.T) {
+	suite.Run(t, new(ChallengeBoltRepositoryTestSuite))
+}
+
+func (s *ChallengeBoltRepositoryTestSuite) TestGetNonExistentChallenge() {
+	challenge, err := s.repo.GetChallenge(s.ctx, []byte("non-existent"))
+	assert.ErrorIs(s.T(), err, errors.ErrNotFound)
+	assert.Nil(s.T(), challenge)
+}
+
+func (s *ChallengeBoltRepositoryTestSuite) TestCreateAndGetChallenge() {
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("test-nonce"),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		Signature:  []byte("test-signature"),
+		Solution:   big.NewInt(42),
+	}
+
+	err := s.repo.CreateChallenge(s.ctx, challenge)
+	assert.NoError(s.T(), err)
+
+	stored, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	assert.NoError(s.T(), err)
+	assert.NotNil(s.T(), stored)
+
+	assert.Equal(s.T(), challenge.Complexity.Int64(), stored.Complexity.Int64())
+	assert.Equal(s.T(), challenge.Nonce, stored.Nonce)
+	assert.Equal(s.T(), challenge.ExpiresAt.Unix(), stored.ExpiresAt.Unix())
+	assert.Equal(s.T(), challenge.Signature, stored.Signature)
+	assert.Equal(s.T(), challenge.Solution.Int64(), stored.Solution.Int64())
+}
+
+func (s *ChallengeBoltRepositoryTestSuite) TestCreateDuplicate() {
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("test-nonce"),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		Signature:  []byte("test-signature"),
+	}
+
+	assert.NoError(s.T(), s.repo.CreateChallenge(s.ctx, challenge))
+	assert.ErrorIs(s.T(), s.repo.CreateChallenge(s.ctx, challenge), errors.ErrChallengeExists)
+}
+
+func (s *ChallengeBoltRepositoryTestSuite) TestDeleteChallenge() {
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("test-nonce"),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		Signature:  []byte("test-signature"),
+	}
+
+	require.NoError(s.T(), s.repo.CreateChallenge(s.ctx, challenge))
+	assert.NoError(s.T(), s.repo.DeleteChallenge(s.ctx, challenge.ID()))
+
+	stored, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	assert.ErrorIs(s.T(), err, errors.ErrNotFound)
+	assert.Nil(s.T(), stored)
+
+	assert.ErrorIs(s.T(), s.repo.DeleteChallenge(s.ctx, []byte("non-existent")), errors.ErrNotFound)
+}
+
+func (s *ChallengeBoltRepositoryTestSuite) TestUpdateChallenge() {
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("test-nonce"),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		Signature:  []byte("test-signature"),
+		Status:     domain.StatusPending,
+	}
+
+	require.NoError(s.T(), s.repo.CreateChallenge(s.ctx, challenge))
+
+	stored, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, stored.Version)
+
+	stored.Status = domain.StatusProcessing
+	stored.Attempts = 1
+	assert.NoError(s.T(), s.repo.UpdateChallenge(s.ctx, stored))
+
+	updated, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.StatusProcessing, updated.Status)
+	assert.Equal(s.T(), 1, updated.Attempts)
+	assert.Equal(s.T(), 2, updated.Version)
+}
+
+func (s *ChallengeBoltRepositoryTestSuite) TestUpdateChallenge_VersionConflict() {
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("test-nonce"),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		Signature:  []byte("test-signature"),
+		Status:     domain.StatusPending,
+	}
+	require.NoError(s.T(), s.repo.CreateChallenge(s.ctx, challenge))
+
+	stale, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	require.NoError(s.T(), err)
+
+	fresh, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	require.NoError(s.T(), err)
+	fresh.Status = domain.StatusProcessing
+	require.NoError(s.T(), s.repo.UpdateChallenge(s.ctx, fresh))
+
+	stale.Status = domain.StatusInvalid
+	assert.ErrorIs(s.T(), s.repo.UpdateChallenge(s.ctx, stale), errors.ErrConflict)
+}
+
+func (s *ChallengeBoltRepositoryTestSuite) TestGetChallenge_Expired() {
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("expired-nonce"),
+		ExpiresAt:  time.Now().Add(-time.Minute),
+		Signature:  []byte("test-signature"),
+	}
+	require.NoError(s.T(), s.repo.CreateChallenge(s.ctx, challenge))
+
+	stored, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	assert.ErrorIs(s.T(), err, errors.ErrChallengeExpired)
+	assert.Nil(s.T(), stored)
+
+	// The expired entry is evicted on lookup, so a second Get is ErrNotFound
+	stored, err = s.repo.GetChallenge(s.ctx, challenge.ID())
+	assert.ErrorIs(s.T(), err, errors.ErrNotFound)
+	assert.Nil(s.T(), stored)
+}
+
+func (s *ChallengeBoltRepositoryTestSuite) TestBackgroundSweepEvictsExpiredChallenges() {
+	repo, err := NewChallengeBoltRepositoryWithSweep(filepath.Join(s.T().TempDir(), "sweep.db"), 10*time.Millisecond)
+	require.NoError(s.T(), err)
+	defer repo.(io.Closer).Close()
+
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("soon-to-expire-nonce"),
+		ExpiresAt:  time.Now().Add(20 * time.Millisecond),
+		Signature:  []byte("test-signature"),
+	}
+	require.NoError(s.T(), repo.CreateChallenge(s.ctx, challenge))
+
+	// Inspect the bucket directly rather than through GetChallenge, which
+	// would evict an expired entry itself and so pass without any sweep
+	boltRepo := repo.(*challengeBoltRepository)
+	assert.Eventually(s.T(), func() bool {
+		var exists bool
+		_ = boltRepo.db.View(func(tx *bbolt.Tx) error {
+			exists = tx.Bucket(challengesBucket).Get([]byte(getHexID(challenge))) != nil
+			return nil
+		})
+		return !exists
+	}, time.Second, 5*time.Millisecond, "sweeper should have evicted the expired challenge")
+}
+
+func (s *ChallengeBoltRepositoryTestSuite) TestPersistsAcrossReopen() {
+	path := filepath.Join(s.T().TempDir(), "reopen.db")
+	repo, err := NewChallengeBoltRepository(path)
+	require.NoError(s.T(), err)
+
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("persistent-nonce"),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		Signature:  []byte("test-signature"),
+	}
+	require.NoError(s.T(), repo.CreateChallenge(s.ctx, challenge))
+	require.NoError(s.T(), repo.(io.Closer).Close())
+
+	reopened, err := NewChallengeBoltRepository(path)
+	require.NoError(s.T(), err)
+	defer reopened.(io.Closer).Close()
+
+	stored, err := reopened.GetChallenge(s.ctx, challenge.ID())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), challenge.Nonce, stored.Nonce)
+}
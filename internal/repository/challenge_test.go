@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"io"
 	"math/big"
 	"testing"
 	"time"
@@ -24,6 +25,12 @@ func (s *ChallengeRepositoryTestSuite) SetupTest() {
 	s.ctx = context.Background()
 }
 
+func (s *ChallengeRepositoryTestSuite) TearDownTest() {
+	if closer, ok := s.repo.(io.Closer); ok {
+		assert.NoError(s.T(), closer.Close())
+	}
+}
+
 func TestChallengeRepository(t *testing.T) {
 	suite.Run(t, new(ChallengeRepositoryTestSuite))
 }
@@ -136,3 +143,121 @@ func (s *ChallengeRepositoryTestSuite) TestChallengeImmutability() {
 	assert.Equal(s.T(), []byte("test-signature"), stored.Signature)
 	assert.Equal(s.T(), int64(42), stored.Solution.Int64())
 }
+
+func (s *ChallengeRepositoryTestSuite) TestUpdateChallenge() {
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("test-nonce"),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		Signature:  []byte("test-signature"),
+		Status:     domain.StatusPending,
+	}
+
+	err := s.repo.CreateChallenge(s.ctx, challenge)
+	assert.NoError(s.T(), err)
+
+	stored, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, stored.Version)
+
+	stored.Status = domain.StatusProcessing
+	stored.Attempts = 1
+
+	err = s.repo.UpdateChallenge(s.ctx, stored)
+	assert.NoError(s.T(), err)
+
+	updated, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.StatusProcessing, updated.Status)
+	assert.Equal(s.T(), 1, updated.Attempts)
+	assert.Equal(s.T(), 2, updated.Version)
+}
+
+func (s *ChallengeRepositoryTestSuite) TestUpdateChallenge_VersionConflict() {
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("test-nonce"),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		Signature:  []byte("test-signature"),
+		Status:     domain.StatusPending,
+	}
+
+	err := s.repo.CreateChallenge(s.ctx, challenge)
+	assert.NoError(s.T(), err)
+
+	stale, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	assert.NoError(s.T(), err)
+
+	fresh, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	assert.NoError(s.T(), err)
+	fresh.Status = domain.StatusProcessing
+	assert.NoError(s.T(), s.repo.UpdateChallenge(s.ctx, fresh))
+
+	// stale still holds the original Version, so this update must be rejected
+	stale.Status = domain.StatusInvalid
+	err = s.repo.UpdateChallenge(s.ctx, stale)
+	assert.ErrorIs(s.T(), err, errors.ErrConflict)
+}
+
+func (s *ChallengeRepositoryTestSuite) TestUpdateChallenge_NotFound() {
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("non-existent-nonce"),
+		ExpiresAt:  time.Now().Add(time.Hour),
+		Signature:  []byte("test-signature"),
+		Version:    1,
+	}
+
+	err := s.repo.UpdateChallenge(s.ctx, challenge)
+	assert.ErrorIs(s.T(), err, errors.ErrNotFound)
+}
+
+func (s *ChallengeRepositoryTestSuite) TestGetChallenge_Expired() {
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("expired-nonce"),
+		ExpiresAt:  time.Now().Add(-time.Minute),
+		Signature:  []byte("test-signature"),
+	}
+
+	err := s.repo.CreateChallenge(s.ctx, challenge)
+	assert.NoError(s.T(), err)
+
+	stored, err := s.repo.GetChallenge(s.ctx, challenge.ID())
+	assert.ErrorIs(s.T(), err, errors.ErrChallengeExpired)
+	assert.Nil(s.T(), stored)
+
+	// The expired entry is evicted on lookup, so a second Get is ErrNotFound
+	stored, err = s.repo.GetChallenge(s.ctx, challenge.ID())
+	assert.ErrorIs(s.T(), err, errors.ErrNotFound)
+	assert.Nil(s.T(), stored)
+}
+
+func (s *ChallengeRepositoryTestSuite) TestBackgroundSweepEvictsExpiredChallenges() {
+	repo := NewChallengeMemoryRepositoryWithSweep(10 * time.Millisecond)
+	defer repo.(io.Closer).Close()
+
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(100),
+		Nonce:      []byte("soon-to-expire-nonce"),
+		ExpiresAt:  time.Now().Add(20 * time.Millisecond),
+		Signature:  []byte("test-signature"),
+	}
+	err := repo.CreateChallenge(s.ctx, challenge)
+	assert.NoError(s.T(), err)
+
+	assert.Eventually(s.T(), func() bool {
+		memRepo := repo.(*challengeMemoryRepository)
+		memRepo.RLock()
+		defer memRepo.RUnlock()
+		_, exists := memRepo.challenges[getHexID(challenge)]
+		return !exists
+	}, time.Second, 5*time.Millisecond, "sweeper should have evicted the expired challenge")
+}
+
+func (s *ChallengeRepositoryTestSuite) TestClose_Idempotent() {
+	repo := NewChallengeMemoryRepositoryWithSweep(time.Hour)
+	closer := repo.(io.Closer)
+	assert.NoError(s.T(), closer.Close())
+	assert.NoError(s.T(), closer.Close())
+}
@@ -0,0 +1,11 @@
+package repository
+
+// Storage driver names accepted by bootstrap.App.Config.StorageDriver
+const (
+	// DriverMemory keeps state in process memory; it is lost on restart
+	DriverMemory = "memory"
+
+	// DriverBolt persists state to an embedded BoltDB file so it survives
+	// a restart
+	DriverBolt = "bolt"
+)
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"word-of-wisdom/internal/domain"
+	"word-of-wisdom/internal/errors"
+	"word-of-wisdom/internal/kit"
+)
+
+// quotesBucket holds one entry per quote, keyed by an 8-byte big-endian
+// sequence number handed out by bbolt's NextSequence. quotesByHashBucket is
+// a secondary index from sha256(text|author) to that sequence number, used
+// only to reject duplicates in O(1) instead of scanning every quote
+var (
+	quotesBucket       = []byte("quotes")
+	quotesByHashBucket = []byte("quotes_by_hash")
+)
+
+// quoteBoltRepository implements domain.QuoteRepository on top of an
+// embedded BoltDB file, so quotes survive a server restart
+type quoteBoltRepository struct {
+	db *bbolt.DB
+}
+
+// NewQuoteBoltRepository opens (creating if necessary) a BoltDB file at path
+// and returns a QuoteRepository backed by it. Callers own the returned
+// repository and must call Close to release the file
+func NewQuoteBoltRepository(path string) (domain.QuoteRepository, error) {
+	kit.Logger.Infow("initializing bolt quote repository", "path", path)
+
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(quotesBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(quotesByHashBucket)
+		return err
+	}); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &quoteBoltRepository{db: db}, nil
+}
+
+// Close releases the underlying BoltDB file
+func (r *quoteBoltRepository) Close() error {
+	return r.db.Close()
+}
+
+// quoteHash derives the secondary-index key for a quote, identifying it by
+// content rather than position so the same quote can't be added twice
+func quoteHash(quote *domain.Quote) []byte {
+	sum := sha256.Sum256([]byte(quote.Text + "|" + quote.Author))
+	return []byte(hex.EncodeToString(sum[:]))
+}
+
+// CreateQuote stores a new quote, rejecting it with errors.ErrQuoteExists if
+// a quote with the same text and author is already stored
+func (r *quoteBoltRepository) CreateQuote(ctx context.Context, quote *domain.Quote) error {
+	hash := quoteHash(quote)
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		byHash := tx.Bucket(quotesByHashBucket)
+		if byHash.Get(hash) != nil {
+			return errors.ErrQuoteExists
+		}
+
+		quotes := tx.Bucket(quotesBucket)
+		seq, err := quotes.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+
+		data, err := json.Marshal(quote)
+		if err != nil {
+			return err
+		}
+		if err := quotes.Put(key, data); err != nil {
+			return err
+		}
+		return byHash.Put(hash, key)
+	})
+	if err != nil {
+		kit.Logger.Errorw("failed to create quote", "author", quote.Author, "error", err)
+		return err
+	}
+
+	kit.Logger.Infow("added new quote", "author", quote.Author)
+	return nil
+}
+
+// GetRandomQuote returns a random quote from the storage
+func (r *quoteBoltRepository) GetRandomQuote(ctx context.Context) (*domain.Quote, error) {
+	var quote *domain.Quote
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(quotesBucket)
+		count := bucket.Stats().KeyN
+		if count == 0 {
+			return errors.ErrNoQuotes
+		}
+
+		target := rand.Intn(count)
+		c := bucket.Cursor()
+		i := 0
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if i == target {
+				var stored domain.Quote
+				if err := json.Unmarshal(v, &stored); err != nil {
+					return err
+				}
+				quote = &stored
+				return nil
+			}
+			i++
+		}
+		return errors.ErrNoQuotes
+	})
+	if err != nil {
+		kit.Logger.Errorw("failed to get random quote", "error", err)
+		return nil, err
+	}
+
+	kit.Logger.Infow("retrieved random quote", "author", quote.Author)
+	return quote, nil
+}
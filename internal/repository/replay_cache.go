@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"container/heap"
+	"context"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"word-of-wisdom/internal/domain"
+	"word-of-wisdom/internal/errors"
+	"word-of-wisdom/internal/kit"
+)
+
+// replayCacheMemory implements domain.ReplayCache with an in-memory map.
+// Unlike challengeMemoryRepository it never stores a Challenge itself —
+// only the hex-encoded IDs a StatelessChallengeService has already redeemed
+// — so its footprint per entry is a fraction of a full challenge record
+type replayCacheMemory struct {
+	sync.RWMutex
+	redeemed map[string]time.Time // hex-encoded id -> expiresAt
+	expiry   expiryEntryHeap      // min-heap of ids by expiresAt, for O(k log n) sweeps
+
+	stopSweep chan struct{}
+	closeOnce sync.Once
+}
+
+// redeemedEntry is a single id's position in expiryEntryHeap
+type redeemedEntry struct {
+	hexID     string
+	expiresAt time.Time
+}
+
+// expiryEntryHeap orders redeemedEntry values by soonest expiresAt first
+type expiryEntryHeap []redeemedEntry
+
+func (h expiryEntryHeap) Len() int            { return len(h) }
+func (h expiryEntryHeap) Less(i, j int) bool  { return h[i].expiresAt.Before(h[j].expiresAt) }
+func (h expiryEntryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *expiryEntryHeap) Push(x interface{}) { *h = append(*h, x.(redeemedEntry)) }
+func (h *expiryEntryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	*h = old[:n-1]
+	return entry
+}
+
+// NewReplayCacheMemory creates a new in-memory domain.ReplayCache with a
+// background sweeper that evicts expired entries every 30 seconds
+func NewReplayCacheMemory() domain.ReplayCache {
+	return NewReplayCacheMemoryWithSweep(defaultSweepInterval)
+}
+
+// NewReplayCacheMemoryWithSweep is like NewReplayCacheMemory but lets the
+// caller control the sweep interval, mirroring
+// NewChallengeMemoryRepositoryWithSweep. Callers own the returned cache and
+// must call Close to stop the sweeper
+func NewReplayCacheMemoryWithSweep(interval time.Duration) domain.ReplayCache {
+	kit.Logger.Infow("initializing in-memory replay cache", "sweep_interval", interval)
+
+	r := &replayCacheMemory{
+		redeemed:  make(map[string]time.Time),
+		stopSweep: make(chan struct{}),
+	}
+	go r.sweepLoop(interval)
+	return r
+}
+
+// sweepLoop periodically evicts expired entries until Close is called
+func (r *replayCacheMemory) sweepLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.sweep()
+		case <-r.stopSweep:
+			return
+		}
+	}
+}
+
+// sweep removes every entry whose expiresAt is in the past, walking the
+// expiry heap so the cost is O(k log n) in the number of expired entries
+func (r *replayCacheMemory) sweep() {
+	r.Lock()
+	defer r.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for r.expiry.Len() > 0 && !r.expiry[0].expiresAt.After(now) {
+		entry := heap.Pop(&r.expiry).(redeemedEntry)
+
+		if expiresAt, exists := r.redeemed[entry.hexID]; exists && !expiresAt.After(entry.expiresAt) {
+			delete(r.redeemed, entry.hexID)
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		kit.Logger.Infow("swept expired replay cache entries", "count", removed)
+	}
+}
+
+// Close stops the background sweeper goroutine. Safe to call multiple times
+func (r *replayCacheMemory) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.stopSweep)
+	})
+	return nil
+}
+
+// MarkRedeemed records id as redeemed until expiresAt, rejecting a second
+// redemption of the same id while the first hasn't expired yet
+func (r *replayCacheMemory) MarkRedeemed(ctx context.Context, id []byte, expiresAt time.Time) error {
+	r.Lock()
+	defer r.Unlock()
+
+	hexID := hex.EncodeToString(id)
+
+	if existing, exists := r.redeemed[hexID]; exists && time.Now().Before(existing) {
+		kit.Logger.Errorw("rejected replayed challenge solution", "id", hexID)
+		return errors.ErrAlreadyRedeemed
+	}
+
+	r.redeemed[hexID] = expiresAt
+	heap.Push(&r.expiry, redeemedEntry{hexID: hexID, expiresAt: expiresAt})
+
+	kit.Logger.Infow("marked challenge solution as redeemed", "id", hexID, "expires_at", expiresAt)
+	return nil
+}
@@ -11,47 +11,106 @@ import (
 	"word-of-wisdom/internal/kit"
 )
 
+// retryBackoffBase is the base delay of the exponential backoff applied to
+// RetryAfter after a failed Verify attempt: base * 2^(attempts-1)
+const retryBackoffBase = time.Second
+
 type challengeService struct {
-	challengeRepository domain.ChallengeRepository
-	secret              []byte
-	complexity          *big.Int
-	expirationTime      time.Duration
+	challengeRepository  domain.ChallengeRepository
+	verifier             *domain.ChallengeVerifier
+	complexityController domain.AdaptiveComplexityController
+	expirationTime       time.Duration
+	providers            map[string]domain.ChallengeProvider
+	defaultAlgorithm     string
+	maxAttempts          int
 }
 
-// NewChallengeService creates a new instance of challenge service
+// NewChallengeService creates a new instance of challenge service.
+// verifier supplies both the signing Keyring (Sign uses verifier.Keyring)
+// and the Clock used for every expiry and Retry-After check, so tests can
+// swap in a FakeClock. providers is keyed by ChallengeProvider.Type();
+// defaultAlgorithm selects which one Generate uses when the caller doesn't
+// request a specific algorithm, and must be present in providers.
+// maxAttempts bounds how many times Verify may be retried before a
+// challenge becomes permanently invalid. complexityController supplies the
+// per-request PoW complexity in place of a static value, adjusting it to
+// recent server load
 func NewChallengeService(
 	challengeRepository domain.ChallengeRepository,
-	secret []byte,
-	complexity *big.Int,
+	verifier *domain.ChallengeVerifier,
+	complexityController domain.AdaptiveComplexityController,
 	expirationTime time.Duration,
+	providers map[string]domain.ChallengeProvider,
+	defaultAlgorithm string,
+	maxAttempts int,
 ) domain.ChallengeService {
 	return &challengeService{
-		challengeRepository: challengeRepository,
-		secret:              secret,
-		complexity:          complexity,
-		expirationTime:      expirationTime,
+		challengeRepository:  challengeRepository,
+		verifier:             verifier,
+		complexityController: complexityController,
+		expirationTime:       expirationTime,
+		providers:            providers,
+		defaultAlgorithm:     defaultAlgorithm,
+		maxAttempts:          maxAttempts,
+	}
+}
+
+// backoff returns the exponential Retry-After delay for the given attempt count
+func backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
 	}
+	return retryBackoffBase * time.Duration(1<<uint(attempts-1))
 }
 
-// Generate creates a new challenge with random nonce and configured complexity
-func (s *challengeService) Generate(ctx context.Context) (*domain.Challenge, error) {
+// provider resolves the ChallengeProvider for the given algorithm name,
+// falling back to the service's configured default when name is empty
+func (s *challengeService) provider(name string) (domain.ChallengeProvider, error) {
+	return resolveProvider(s.providers, s.defaultAlgorithm, name)
+}
+
+// resolveProvider looks up name in providers, falling back to
+// defaultAlgorithm when name is empty. Shared by every ChallengeService
+// implementation so they agree on algorithm resolution
+func resolveProvider(providers map[string]domain.ChallengeProvider, defaultAlgorithm, name string) (domain.ChallengeProvider, error) {
+	if name == "" {
+		name = defaultAlgorithm
+	}
+	provider, ok := providers[name]
+	if !ok {
+		return nil, errors.ErrUnknownAlgorithm
+	}
+	return provider, nil
+}
+
+// Generate creates a new challenge using the requested algorithm (or the
+// configured default), with a complexity the AdaptiveComplexityController
+// selects for clientAddr based on recent server load and that client's history
+func (s *challengeService) Generate(ctx context.Context, algorithm string, clientAddr string) (*domain.Challenge, error) {
 	kit.Logger.Info("challenge service: generating new challenge")
 
-	// Generate random nonce
-	nonce, err := domain.GenerateNonce()
+	provider, err := s.provider(algorithm)
 	if err != nil {
+		kit.Logger.Errorw("failed to resolve challenge provider",
+			"algorithm", algorithm,
+			"error", err)
 		return nil, err
 	}
 
-	// Create challenge with configured parameters
-	challenge := &domain.Challenge{
-		Complexity: new(big.Int).Set(s.complexity),
-		Nonce:      nonce,
-		ExpiresAt:  time.Now().Add(s.expirationTime),
+	challenge, err := provider.Generate(ctx, domain.ChallengeParams{
+		Complexity: s.complexityController.Complexity(clientAddr),
+		ExpiresIn:  s.expirationTime,
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	// Start the validation state machine in the pending state
+	challenge.Status = domain.StatusPending
+	challenge.MaxAttempts = s.maxAttempts
+
 	// Sign the challenge
-	challenge.Sign(s.secret)
+	challenge.Sign(s.verifier.Keyring)
 
 	// Store the challenge
 	if err := s.challengeRepository.CreateChallenge(ctx, challenge); err != nil {
@@ -62,13 +121,14 @@ func (s *challengeService) Generate(ctx context.Context) (*domain.Challenge, err
 
 	kit.Logger.Infow("generated new challenge",
 		"id", challenge.ID(),
+		"algorithm", challenge.Algorithm,
 		"complexity", challenge.Complexity,
 		"expires_at", challenge.ExpiresAt)
 	return challenge, nil
 }
 
 // Verify checks if the provided solution is valid for the challenge
-func (s *challengeService) Verify(ctx context.Context, challengeID []byte, solution *big.Int) error {
+func (s *challengeService) Verify(ctx context.Context, challengeID []byte, solution *big.Int, clientAddr string) error {
 	kit.Logger.Info("challenge service: verifying solution")
 
 	// 1. Get challenge from repository
@@ -81,17 +141,86 @@ func (s *challengeService) Verify(ctx context.Context, challengeID []byte, solut
 	}
 
 	// 2. Verify challenge signature
-	if !challenge.VerifySignature(s.secret) {
+	if !s.verifier.VerifySignature(challenge) {
 		kit.Logger.Errorw("challenge signature verification failed",
 			"id", challengeID)
 		return errors.ErrInvalidChallenge
 	}
 
-	// 3. Verify solution
-	if !challenge.VerifySolution(solution) {
+	// 3. A terminal challenge (valid or invalid) cannot be retried
+	if challenge.Status.IsTerminal() {
+		kit.Logger.Errorw("challenge is already terminal",
+			"id", challengeID,
+			"status", challenge.Status)
+		return errors.ErrChallengeTerminal
+	}
+
+	// 4. Respect the Retry-After hint from a previous failed attempt
+	if !challenge.RetryAfter.IsZero() && s.verifier.Clock.Now().Before(challenge.RetryAfter) {
+		return &errors.RetryError{
+			Attempts:   challenge.Attempts,
+			RetryAfter: challenge.RetryAfter,
+			Err:        errors.ErrInvalidSolution,
+		}
+	}
+
+	// 5. Resolve the provider that generated this challenge
+	provider, err := s.provider(challenge.Algorithm)
+	if err != nil {
+		kit.Logger.Errorw("failed to resolve challenge provider",
+			"id", challengeID,
+			"algorithm", challenge.Algorithm,
+			"error", err)
+		return err
+	}
+
+	// 6. Verify solution and transition state accordingly
+	if !provider.Verify(challenge, solution) {
+		s.complexityController.RecordOutcome(clientAddr, domain.OutcomeFailed)
+
+		challenge.Attempts++
+		if challenge.Attempts >= challenge.MaxAttempts {
+			challenge.Status = domain.StatusInvalid
+			challenge.RetryAfter = time.Time{}
+		} else {
+			challenge.Status = domain.StatusProcessing
+			challenge.RetryAfter = s.verifier.Clock.Now().Add(backoff(challenge.Attempts))
+		}
+		challenge.Sign(s.verifier.Keyring)
+
+		if err := s.challengeRepository.UpdateChallenge(ctx, challenge); err != nil {
+			kit.Logger.Errorw("failed to persist challenge state",
+				"id", challengeID,
+				"error", err)
+			return err
+		}
+
 		kit.Logger.Errorw("invalid solution for challenge",
-			"id", challengeID)
-		return errors.ErrInvalidSolution
+			"id", challengeID,
+			"attempts", challenge.Attempts,
+			"status", challenge.Status)
+
+		if challenge.Status == domain.StatusInvalid {
+			return errors.ErrInvalidSolution
+		}
+		return &errors.RetryError{
+			Attempts:   challenge.Attempts,
+			RetryAfter: challenge.RetryAfter,
+			Err:        errors.ErrInvalidSolution,
+		}
+	}
+
+	s.complexityController.RecordOutcome(clientAddr, domain.OutcomeSolved)
+
+	challenge.Status = domain.StatusValid
+	challenge.RetryAfter = time.Time{}
+	challenge.Sign(s.verifier.Keyring)
+
+	if err := s.challengeRepository.UpdateChallenge(ctx, challenge); err != nil {
+		kit.Logger.Errorw("failed to persist challenge state",
+			"id", challengeID,
+			"error", err)
+		return err
 	}
 
 	return nil
@@ -102,43 +231,41 @@ func (s *challengeService) Solve(ctx context.Context, challenge *domain.Challeng
 	kit.Logger.Info("challenge service: solving challenge")
 
 	// 1. Check if challenge has expired
-	if time.Now().After(challenge.ExpiresAt) {
+	if s.verifier.Clock.Now().After(challenge.ExpiresAt) {
 		kit.Logger.Errorw("challenge has expired",
 			"id", challenge.ID())
 		return nil, errors.ErrChallengeExpired
 	}
 
 	// 2. Verify challenge signature
-	if !challenge.VerifySignature(s.secret) {
+	if !s.verifier.VerifySignature(challenge) {
 		kit.Logger.Errorw("invalid challenge signature",
 			"id", challenge.ID())
 		return nil, errors.ErrInvalidChallenge
 	}
 
-	// 3. Try to find a solution
-	solution := big.NewInt(0)
-	maxIterations := int64(1000000) // Limit iterations to prevent infinite loop
-
-	for i := int64(0); i < maxIterations; i++ {
-		select {
-		case <-ctx.Done():
-			kit.Logger.Warn("context cancelled while solving challenge")
-			return nil, ctx.Err()
-		default:
-			if challenge.VerifySolution(solution) {
-				kit.Logger.Infow("found valid solution",
-					"id", challenge.ID(),
-					"solution", solution.String())
-				return solution, nil
-			}
-			solution.Add(solution, big.NewInt(1))
-		}
+	// 3. Resolve the provider and delegate solving to it
+	provider, err := s.provider(challenge.Algorithm)
+	if err != nil {
+		kit.Logger.Errorw("failed to resolve challenge provider",
+			"id", challenge.ID(),
+			"algorithm", challenge.Algorithm,
+			"error", err)
+		return nil, err
+	}
+
+	solution, err := provider.Solve(ctx, challenge)
+	if err != nil {
+		kit.Logger.Errorw("failed to find solution",
+			"id", challenge.ID(),
+			"error", err)
+		return nil, err
 	}
 
-	kit.Logger.Errorw("failed to find solution within iteration limit",
+	kit.Logger.Infow("found valid solution",
 		"id", challenge.ID(),
-		"max_iterations", maxIterations)
-	return nil, errors.ErrSolutionNotFound
+		"solution", solution.String())
+	return solution, nil
 }
 
 // calculateSolutionHash computes SHA-256 hash of challenge ID concatenated with solution
@@ -9,28 +9,46 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
+	"word-of-wisdom/internal/difficulty"
 	"word-of-wisdom/internal/domain"
 	"word-of-wisdom/internal/errors"
+	"word-of-wisdom/internal/pow"
 	"word-of-wisdom/internal/repository"
 )
 
+// testClientAddr is the clientAddr passed to Generate/Verify by tests that
+// don't care about per-client complexity scaling
+const testClientAddr = "test-client"
+
 type ChallengeServiceTestSuite struct {
 	suite.Suite
 	service     domain.ChallengeService
 	repository  domain.ChallengeRepository
 	ctx         context.Context
-	secret      []byte
+	secret      domain.Keyring
+	clock       *domain.FakeClock
+	verifier    *domain.ChallengeVerifier
 	complexity  *big.Int
 	expiration  time.Duration
+	maxAttempts int
+}
+
+// newTestComplexityController returns a controller pinned at complexity, so
+// tests can assert on an exact value rather than a retargeted one
+func newTestComplexityController(complexity *big.Int) domain.AdaptiveComplexityController {
+	return difficulty.NewController(complexity, complexity, time.Minute, 1)
 }
 
 func (s *ChallengeServiceTestSuite) SetupTest() {
 	s.repository = repository.NewChallengeMemoryRepository()
 	s.ctx = context.Background()
-	s.secret = []byte("test-secret")
+	s.secret = domain.NewStaticKeyring([]byte("test-secret"))
+	s.clock = domain.NewFakeClock(time.Now())
+	s.verifier = &domain.ChallengeVerifier{Keyring: s.secret, Clock: s.clock}
 	s.complexity = big.NewInt(1) // Use small complexity for testing
 	s.expiration = 5 * time.Minute
-	s.service = NewChallengeService(s.repository, s.secret, s.complexity, s.expiration)
+	s.maxAttempts = 1
+	s.service = NewChallengeService(s.repository, s.verifier, newTestComplexityController(s.complexity), s.expiration, pow.DefaultProviders(s.clock), pow.AlgorithmSHA256, s.maxAttempts)
 }
 
 func TestChallengeService(t *testing.T) {
@@ -38,15 +56,15 @@ func TestChallengeService(t *testing.T) {
 }
 
 func (s *ChallengeServiceTestSuite) TestGenerate_Success() {
-	challenge, err := s.service.Generate(s.ctx)
-	
+	challenge, err := s.service.Generate(s.ctx, "", testClientAddr)
+
 	assert.NoError(s.T(), err)
 	assert.NotNil(s.T(), challenge)
 	assert.Equal(s.T(), s.complexity.Int64(), challenge.Complexity.Int64())
 	assert.NotNil(s.T(), challenge.Nonce)
 	assert.NotNil(s.T(), challenge.Signature)
-	assert.True(s.T(), challenge.ExpiresAt.After(time.Now()))
-	assert.True(s.T(), challenge.ExpiresAt.Before(time.Now().Add(s.expiration).Add(time.Second)))
+	assert.True(s.T(), challenge.ExpiresAt.After(s.clock.Now()))
+	assert.True(s.T(), challenge.ExpiresAt.Before(s.clock.Now().Add(s.expiration).Add(time.Second)))
 
 	// Verify we can retrieve the challenge from repository
 	stored, err := s.repository.GetChallenge(s.ctx, challenge.ID())
@@ -56,24 +74,24 @@ func (s *ChallengeServiceTestSuite) TestGenerate_Success() {
 
 func (s *ChallengeServiceTestSuite) TestVerify_Success() {
 	// Generate a challenge first
-	challenge, err := s.service.Generate(s.ctx)
+	challenge, err := s.service.Generate(s.ctx, "", testClientAddr)
 	assert.NoError(s.T(), err)
 
 	// Solve the challenge
 	solution := big.NewInt(0)
 	found := false
 	for i := int64(0); i < 1000000; i++ {
-		if challenge.VerifySolution(solution) {
+		if s.verifier.VerifySolution(challenge, solution) {
 			found = true
 			break
 		}
 		solution.Add(solution, big.NewInt(1))
 	}
 	assert.True(s.T(), found, "Failed to find a valid solution")
-	assert.True(s.T(), challenge.VerifySolution(solution), "Solution verification failed")
+	assert.True(s.T(), s.verifier.VerifySolution(challenge, solution), "Solution verification failed")
 
 	// Verify the solution
-	err = s.service.Verify(s.ctx, challenge.ID(), solution)
+	err = s.service.Verify(s.ctx, challenge.ID(), solution, testClientAddr)
 	assert.NoError(s.T(), err)
 }
 
@@ -81,15 +99,31 @@ func (s *ChallengeServiceTestSuite) TestVerify_NotFound() {
 	challengeID := []byte("non-existent")
 	solution := big.NewInt(42)
 
-	err := s.service.Verify(s.ctx, challengeID, solution)
-	
+	err := s.service.Verify(s.ctx, challengeID, solution, testClientAddr)
+
 	assert.Error(s.T(), err)
 	assert.Equal(s.T(), errors.ErrNotFound, err)
 }
 
+func (s *ChallengeServiceTestSuite) TestVerify_ChallengeExpired() {
+	challenge, err := s.service.Generate(s.ctx, "", testClientAddr)
+	assert.NoError(s.T(), err)
+
+	err = s.repository.DeleteChallenge(s.ctx, challenge.ID())
+	assert.NoError(s.T(), err)
+
+	challenge.ExpiresAt = s.clock.Now().Add(-time.Hour)
+	challenge.Sign(s.secret)
+	err = s.repository.CreateChallenge(s.ctx, challenge)
+	assert.NoError(s.T(), err)
+
+	err = s.service.Verify(s.ctx, challenge.ID(), big.NewInt(42), testClientAddr)
+	assert.ErrorIs(s.T(), err, errors.ErrChallengeExpired)
+}
+
 func (s *ChallengeServiceTestSuite) TestVerify_InvalidSignature() {
 	// Generate a challenge first
-	challenge, err := s.service.Generate(s.ctx)
+	challenge, err := s.service.Generate(s.ctx, "", testClientAddr)
 	assert.NoError(s.T(), err)
 
 	// Delete the original challenge
@@ -103,21 +137,25 @@ func (s *ChallengeServiceTestSuite) TestVerify_InvalidSignature() {
 
 	// Try to verify with any solution
 	solution := big.NewInt(42)
-	err = s.service.Verify(s.ctx, challenge.ID(), solution)
-	
+	err = s.service.Verify(s.ctx, challenge.ID(), solution, testClientAddr)
+
 	assert.Error(s.T(), err)
 	assert.Equal(s.T(), errors.ErrInvalidChallenge, err)
 }
 
 func (s *ChallengeServiceTestSuite) TestVerify_InvalidSolution() {
+	// See TestVerify_RetryableBeforeMaxAttempts: a high complexity makes the
+	// fixed wrongSolution below actually wrong under the bit-granular target
+	svc := NewChallengeService(s.repository, s.verifier, newTestComplexityController(big.NewInt(250)), s.expiration, pow.DefaultProviders(s.clock), pow.AlgorithmSHA256, s.maxAttempts)
+
 	// Generate a challenge first
-	challenge, err := s.service.Generate(s.ctx)
+	challenge, err := svc.Generate(s.ctx, "", testClientAddr)
 	assert.NoError(s.T(), err)
 
 	// Try to verify with wrong solution
 	wrongSolution := big.NewInt(999)
-	err = s.service.Verify(s.ctx, challenge.ID(), wrongSolution)
-	
+	err = svc.Verify(s.ctx, challenge.ID(), wrongSolution, testClientAddr)
+
 	assert.Error(s.T(), err)
 	assert.Equal(s.T(), errors.ErrInvalidSolution, err)
 }
@@ -127,30 +165,30 @@ func (s *ChallengeServiceTestSuite) TestSolve_Success() {
 	challenge := &domain.Challenge{
 		Complexity: big.NewInt(1),
 		Nonce:      []byte("test-nonce"),
-		ExpiresAt:  time.Now().Add(time.Hour),
+		ExpiresAt:  s.clock.Now().Add(time.Hour),
 	}
 	challenge.Sign(s.secret)
 
 	solution, err := s.service.Solve(s.ctx, challenge)
-	
+
 	assert.NoError(s.T(), err)
 	assert.NotNil(s.T(), solution)
-	assert.True(s.T(), challenge.VerifySolution(solution))
+	assert.True(s.T(), s.verifier.VerifySolution(challenge, solution))
 }
 
 func (s *ChallengeServiceTestSuite) TestSolve_ExpiredChallenge() {
 	// First generate a valid challenge
-	challenge, err := s.service.Generate(s.ctx)
+	challenge, err := s.service.Generate(s.ctx, "", testClientAddr)
 	assert.NoError(s.T(), err)
 
 	// Modify its expiration time to make it expired
-	challenge.ExpiresAt = time.Now().Add(-time.Hour)
+	challenge.ExpiresAt = s.clock.Now().Add(-time.Hour)
 	err = s.repository.CreateChallenge(s.ctx, challenge)
 	assert.NoError(s.T(), err)
 
 	// Try to solve the expired challenge
 	solution, err := s.service.Solve(s.ctx, challenge)
-	
+
 	assert.Error(s.T(), err)
 	assert.Equal(s.T(), errors.ErrChallengeExpired, err)
 	assert.Nil(s.T(), solution)
@@ -160,13 +198,105 @@ func (s *ChallengeServiceTestSuite) TestSolve_InvalidSignature() {
 	challenge := &domain.Challenge{
 		Complexity: s.complexity,
 		Nonce:      []byte("test-nonce"),
-		ExpiresAt:  time.Now().Add(time.Hour),
+		ExpiresAt:  s.clock.Now().Add(time.Hour),
 		Signature:  []byte("invalid-signature"),
 	}
 
 	solution, err := s.service.Solve(s.ctx, challenge)
-	
+
 	assert.Error(s.T(), err)
 	assert.Equal(s.T(), errors.ErrInvalidChallenge, err)
 	assert.Nil(s.T(), solution)
 }
+
+func (s *ChallengeServiceTestSuite) TestGenerate_ExplicitAlgorithm() {
+	challenge, err := s.service.Generate(s.ctx, pow.AlgorithmEquihash, testClientAddr)
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), pow.AlgorithmEquihash, challenge.Algorithm)
+}
+
+func (s *ChallengeServiceTestSuite) TestGenerate_UnknownAlgorithm() {
+	challenge, err := s.service.Generate(s.ctx, "unknown-algorithm", testClientAddr)
+
+	assert.ErrorIs(s.T(), err, errors.ErrUnknownAlgorithm)
+	assert.Nil(s.T(), challenge)
+}
+
+func (s *ChallengeServiceTestSuite) TestVerify_RetryableBeforeMaxAttempts() {
+	// A high complexity, so the fixed wrongSolution below is actually wrong:
+	// under the bit-granular target (domain.ComplexityFromBits), complexity=1
+	// only demands one leading zero bit and a wrong guess would pass ~50% of
+	// the time, which is not what this test means to exercise
+	highComplexity := big.NewInt(250)
+
+	// Use a service with room for more than one attempt
+	svc := NewChallengeService(s.repository, s.verifier, newTestComplexityController(highComplexity), s.expiration, pow.DefaultProviders(s.clock), pow.AlgorithmSHA256, 3)
+
+	challenge, err := svc.Generate(s.ctx, "", testClientAddr)
+	assert.NoError(s.T(), err)
+
+	wrongSolution := big.NewInt(999)
+	err = svc.Verify(s.ctx, challenge.ID(), wrongSolution, testClientAddr)
+
+	var retryErr *errors.RetryError
+	assert.ErrorAs(s.T(), err, &retryErr)
+	assert.Equal(s.T(), 1, retryErr.Attempts)
+	assert.True(s.T(), retryErr.RetryAfter.After(s.clock.Now()))
+
+	stored, err := s.repository.GetChallenge(s.ctx, challenge.ID())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.StatusProcessing, stored.Status)
+}
+
+func (s *ChallengeServiceTestSuite) TestVerify_BecomesInvalidAfterMaxAttempts() {
+	// See TestVerify_RetryableBeforeMaxAttempts: a high complexity makes the
+	// fixed wrongSolution below actually wrong under the bit-granular target
+	highComplexity := big.NewInt(250)
+	svc := NewChallengeService(s.repository, s.verifier, newTestComplexityController(highComplexity), s.expiration, pow.DefaultProviders(s.clock), pow.AlgorithmSHA256, 2)
+
+	challenge, err := svc.Generate(s.ctx, "", testClientAddr)
+	assert.NoError(s.T(), err)
+
+	wrongSolution := big.NewInt(999)
+
+	// Force the Retry-After hint out of the way between attempts
+	err = svc.Verify(s.ctx, challenge.ID(), wrongSolution, testClientAddr)
+	assert.Error(s.T(), err)
+
+	stored, err := s.repository.GetChallenge(s.ctx, challenge.ID())
+	assert.NoError(s.T(), err)
+	stored.RetryAfter = time.Time{}
+	stored.Sign(s.secret)
+	assert.NoError(s.T(), s.repository.UpdateChallenge(s.ctx, stored))
+
+	err = svc.Verify(s.ctx, challenge.ID(), wrongSolution, testClientAddr)
+	assert.ErrorIs(s.T(), err, errors.ErrInvalidSolution)
+
+	stored, err = s.repository.GetChallenge(s.ctx, challenge.ID())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.StatusInvalid, stored.Status)
+
+	// A terminal challenge rejects any further Verify call
+	err = svc.Verify(s.ctx, challenge.ID(), wrongSolution, testClientAddr)
+	assert.ErrorIs(s.T(), err, errors.ErrChallengeTerminal)
+}
+
+func (s *ChallengeServiceTestSuite) TestVerify_RespectsRetryAfter() {
+	// See TestVerify_RetryableBeforeMaxAttempts: a high complexity makes the
+	// fixed wrongSolution below actually wrong under the bit-granular target
+	highComplexity := big.NewInt(250)
+	svc := NewChallengeService(s.repository, s.verifier, newTestComplexityController(highComplexity), s.expiration, pow.DefaultProviders(s.clock), pow.AlgorithmSHA256, 5)
+
+	challenge, err := svc.Generate(s.ctx, "", testClientAddr)
+	assert.NoError(s.T(), err)
+
+	wrongSolution := big.NewInt(999)
+	err = svc.Verify(s.ctx, challenge.ID(), wrongSolution, testClientAddr)
+	assert.Error(s.T(), err)
+
+	// Immediately retrying before RetryAfter elapses must still be rejected
+	err = svc.Verify(s.ctx, challenge.ID(), wrongSolution, testClientAddr)
+	var retryErr *errors.RetryError
+	assert.ErrorAs(s.T(), err, &retryErr)
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"word-of-wisdom/internal/domain"
+	"word-of-wisdom/internal/errors"
+	"word-of-wisdom/internal/kit"
+)
+
+// statelessChallengeService is a domain.ChallengeService that keeps no
+// per-challenge server-side state. Every Challenge it hands out carries its
+// own HMAC-signed validation state (see Challenge.Sign), encoded as a
+// domain.ChallengeToken, so Verify can reach a decision from the token the
+// client sends back alone. This removes the DoS surface where flooding
+// Generate inflates a server-side challenge map, and lets the service scale
+// horizontally with nothing shared between instances beyond the secret and
+// replayCache.
+//
+// Because a token carries no durable Attempts counter that the server could
+// advance and hand back, Verify treats every call as a first attempt: the
+// PoW complexity is the rate limiter here, not a retry budget. MaxAttempts
+// and RetryAfter are still carried in the token, with the same fields a
+// repository-backed challenge has, but are never advanced server-side;
+// RetryAfter is covered by the signature, MaxAttempts is not
+type statelessChallengeService struct {
+	replayCache          domain.ReplayCache
+	verifier             *domain.ChallengeVerifier
+	complexityController domain.AdaptiveComplexityController
+	expirationTime       time.Duration
+	providers            map[string]domain.ChallengeProvider
+	defaultAlgorithm     string
+	maxAttempts          int
+}
+
+// NewStatelessChallengeService creates a domain.ChallengeService that issues
+// self-contained ChallengeTokens instead of storing challenges server-side.
+// replayCache stops a solved token from being redeemed twice; the remaining
+// parameters mirror NewChallengeService
+func NewStatelessChallengeService(
+	replayCache domain.ReplayCache,
+	verifier *domain.ChallengeVerifier,
+	complexityController domain.AdaptiveComplexityController,
+	expirationTime time.Duration,
+	providers map[string]domain.ChallengeProvider,
+	defaultAlgorithm string,
+	maxAttempts int,
+) domain.ChallengeService {
+	return &statelessChallengeService{
+		replayCache:          replayCache,
+		verifier:             verifier,
+		complexityController: complexityController,
+		expirationTime:       expirationTime,
+		providers:            providers,
+		defaultAlgorithm:     defaultAlgorithm,
+		maxAttempts:          maxAttempts,
+	}
+}
+
+// Generate creates a new, signed challenge the same way challengeService
+// does, minus the repository write: the returned Challenge's Marshal is the
+// ChallengeToken a client must present back to Verify
+func (s *statelessChallengeService) Generate(ctx context.Context, algorithm string, clientAddr string) (*domain.Challenge, error) {
+	kit.Logger.Info("stateless challenge service: generating new challenge")
+
+	provider, err := resolveProvider(s.providers, s.defaultAlgorithm, algorithm)
+	if err != nil {
+		kit.Logger.Errorw("failed to resolve challenge provider",
+			"algorithm", algorithm,
+			"error", err)
+		return nil, err
+	}
+
+	challenge, err := provider.Generate(ctx, domain.ChallengeParams{
+		Complexity: s.complexityController.Complexity(clientAddr),
+		ExpiresIn:  s.expirationTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	challenge.Status = domain.StatusPending
+	challenge.MaxAttempts = s.maxAttempts
+	challenge.Sign(s.verifier.Keyring)
+
+	kit.Logger.Infow("generated new challenge",
+		"id", challenge.ID(),
+		"algorithm", challenge.Algorithm,
+		"complexity", challenge.Complexity,
+		"expires_at", challenge.ExpiresAt)
+	return challenge, nil
+}
+
+// Verify decodes token (the ChallengeToken Generate returned), re-checks its
+// HMAC and expiry, and runs the owning provider's VerifySolution. A correct
+// solution is redeemed at most once: the challenge's id is marked in
+// replayCache so the same token can't be presented again for a second quote
+func (s *statelessChallengeService) Verify(ctx context.Context, token []byte, solution *big.Int, clientAddr string) error {
+	kit.Logger.Info("stateless challenge service: verifying solution")
+
+	challenge, err := domain.DecodeChallengeToken(domain.ChallengeToken(token))
+	if err != nil {
+		kit.Logger.Errorw("failed to decode challenge token", "error", err)
+		return errors.ErrInvalidChallenge
+	}
+
+	if !s.verifier.VerifySignature(challenge) {
+		kit.Logger.Errorw("challenge signature verification failed", "id", challenge.ID())
+		return errors.ErrInvalidChallenge
+	}
+
+	if challenge.Status.IsTerminal() {
+		kit.Logger.Errorw("challenge is already terminal",
+			"id", challenge.ID(),
+			"status", challenge.Status)
+		return errors.ErrChallengeTerminal
+	}
+
+	provider, err := resolveProvider(s.providers, s.defaultAlgorithm, challenge.Algorithm)
+	if err != nil {
+		kit.Logger.Errorw("failed to resolve challenge provider",
+			"id", challenge.ID(),
+			"algorithm", challenge.Algorithm,
+			"error", err)
+		return err
+	}
+
+	if !provider.Verify(challenge, solution) {
+		s.complexityController.RecordOutcome(clientAddr, domain.OutcomeFailed)
+		kit.Logger.Errorw("invalid solution for challenge", "id", challenge.ID())
+		return errors.ErrInvalidSolution
+	}
+
+	if err := s.replayCache.MarkRedeemed(ctx, challenge.ID(), challenge.ExpiresAt); err != nil {
+		kit.Logger.Errorw("solution rejected as replayed", "id", challenge.ID(), "error", err)
+		return err
+	}
+
+	s.complexityController.RecordOutcome(clientAddr, domain.OutcomeSolved)
+	return nil
+}
+
+// Solve attempts to find a solution for the given challenge, delegating to
+// the owning provider exactly like challengeService does
+func (s *statelessChallengeService) Solve(ctx context.Context, challenge *domain.Challenge) (*big.Int, error) {
+	kit.Logger.Info("stateless challenge service: solving challenge")
+
+	if s.verifier.Clock.Now().After(challenge.ExpiresAt) {
+		kit.Logger.Errorw("challenge has expired", "id", challenge.ID())
+		return nil, errors.ErrChallengeExpired
+	}
+	if !s.verifier.VerifySignature(challenge) {
+		kit.Logger.Errorw("invalid challenge signature", "id", challenge.ID())
+		return nil, errors.ErrInvalidChallenge
+	}
+
+	provider, err := resolveProvider(s.providers, s.defaultAlgorithm, challenge.Algorithm)
+	if err != nil {
+		kit.Logger.Errorw("failed to resolve challenge provider",
+			"id", challenge.ID(),
+			"algorithm", challenge.Algorithm,
+			"error", err)
+		return nil, err
+	}
+
+	solution, err := provider.Solve(ctx, challenge)
+	if err != nil {
+		kit.Logger.Errorw("failed to find solution", "id", challenge.ID(), "error", err)
+		return nil, err
+	}
+
+	kit.Logger.Infow("found valid solution", "id", challenge.ID(), "solution", solution.String())
+	return solution, nil
+}
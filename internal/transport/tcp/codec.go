@@ -0,0 +1,89 @@
+package tcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"word-of-wisdom/internal/domain"
+	"word-of-wisdom/internal/transport/tcp/pb"
+)
+
+// Content-type negotiation bytes. A client sends one of these right after
+// the command byte to pick how the server encodes its response
+const (
+	contentTypeJSON     byte = 0x00
+	contentTypeProtobuf byte = 0x01
+)
+
+// contentTypeName maps a wire content-type byte to a human-readable name
+// for logging; unknown bytes fall back to JSON
+func contentTypeName(b byte) string {
+	switch b {
+	case contentTypeProtobuf:
+		return "protobuf"
+	default:
+		return "json"
+	}
+}
+
+// marshalResponse encodes data per the client's negotiated content type,
+// falling back to JSON for anything other than contentTypeProtobuf
+func marshalResponse(encoding byte, data interface{}) ([]byte, error) {
+	if encoding == contentTypeProtobuf {
+		return marshalProto(data)
+	}
+	return json.Marshal(data)
+}
+
+// marshalProto encodes data as one of the pb message types. Only the types
+// the server ever hands to writeResponse are supported
+func marshalProto(data interface{}) ([]byte, error) {
+	switch v := data.(type) {
+	case *domain.Challenge:
+		return toPBChallenge(v).Marshal()
+	case *domain.Quote:
+		return toPBQuote(v).Marshal()
+	case errorResponse:
+		return (&pb.ErrorResponse{Error: v.Error}).Marshal()
+	default:
+		return nil, fmt.Errorf("no protobuf encoding for %T", data)
+	}
+}
+
+// errorResponse mirrors the ad-hoc {"error": "..."} object writeError sends
+// over JSON, giving protobuf encoding something concrete to switch on
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// toPBChallenge converts a domain.Challenge to its wire representation.
+// big.Int fields are carried as their big-endian byte encoding, matching
+// how domain.Challenge.ID itself serializes them
+func toPBChallenge(c *domain.Challenge) *pb.Challenge {
+	out := &pb.Challenge{
+		Complexity:      c.Complexity.Bytes(),
+		Nonce:           c.Nonce,
+		ExpiresAt:       c.ExpiresAt.Unix(),
+		Signature:       c.Signature,
+		Algorithm:       c.Algorithm,
+		AlgorithmParams: c.AlgorithmParams,
+		Status:          string(c.Status),
+		RetryAfter:      c.RetryAfter.Unix(),
+		Attempts:        int32(c.Attempts),
+		MaxAttempts:     int32(c.MaxAttempts),
+		Version:         int32(c.Version),
+	}
+	if c.Solution != nil {
+		out.Solution = c.Solution.Bytes()
+	}
+	return out
+}
+
+// toPBQuote converts a domain.Quote to its wire representation
+func toPBQuote(q *domain.Quote) *pb.Quote {
+	return &pb.Quote{
+		Text:   q.Text,
+		Author: q.Author,
+		Source: q.Source,
+	}
+}
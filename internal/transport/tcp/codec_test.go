@@ -0,0 +1,53 @@
+package tcp
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"word-of-wisdom/internal/domain"
+)
+
+func TestMarshalResponse_ProtobufChallengeRoundTrips(t *testing.T) {
+	challenge := &domain.Challenge{
+		Complexity: big.NewInt(4),
+		Nonce:      []byte("nonce"),
+		ExpiresAt:  time.Unix(1700000000, 0),
+		Signature:  []byte("signature"),
+		Solution:   big.NewInt(123456789),
+		Algorithm:  "sha256",
+		Status:     domain.StatusPending,
+	}
+
+	data, err := marshalResponse(contentTypeProtobuf, challenge)
+	require.NoError(t, err)
+
+	decoded := toPBChallenge(challenge)
+	encoded, err := decoded.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, encoded, data)
+
+	roundTripped := decoded
+	roundTripped.Nonce = nil
+	require.NoError(t, roundTripped.Unmarshal(data))
+	assert.Equal(t, challenge.Nonce, roundTripped.Nonce)
+	assert.Equal(t, challenge.Complexity.Bytes(), roundTripped.Complexity)
+	assert.Equal(t, challenge.Solution.Bytes(), roundTripped.Solution)
+	assert.Equal(t, string(challenge.Status), roundTripped.Status)
+}
+
+func TestMarshalResponse_FallsBackToJSON(t *testing.T) {
+	quote := &domain.Quote{Text: "test", Author: "tester"}
+
+	data, err := marshalResponse(0xFF, quote) // an unrecognized content-type byte
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Text":"test"`)
+}
+
+func TestMarshalProto_UnsupportedType(t *testing.T) {
+	_, err := marshalProto(42)
+	assert.Error(t, err)
+}
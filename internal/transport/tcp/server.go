@@ -3,11 +3,12 @@ package tcp
 import (
 	"context"
 	"encoding/binary"
-	"encoding/json"
 	"fmt"
 	"io"
 	"math/big"
 	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"word-of-wisdom/internal/domain"
@@ -20,150 +21,282 @@ const (
 
 	readTimeout  = 10 * time.Second
 	writeTimeout = 10 * time.Second
+
+	// maxChallengeIDLen bounds the length-prefixed challenge identifier a
+	// client may send with cmdGetQuote, so a forged length prefix can't
+	// make the server allocate an unbounded buffer. It comfortably fits the
+	// bare 32-byte Challenge.ID() the repository-backed ChallengeService
+	// expects as well as a marshaled domain.ChallengeToken
+	maxChallengeIDLen = 4096
 )
 
+// Algorithm negotiation bytes sent by the client right after
+// cmdGetChallenge. algByteDefault lets a client defer the choice to
+// whatever the server is configured with
+const (
+	algByteDefault  byte = 0x00
+	algByteSHA256   byte = 0x01
+	algByteScrypt   byte = 0x02
+	algByteEquihash byte = 0x03
+	algByteArgon2id byte = 0x04
+)
+
+// algorithmName maps a wire algorithm byte to a domain.ChallengeProvider
+// Type(). An empty string means "use the server's default"
+func algorithmName(b byte) string {
+	switch b {
+	case algByteSHA256:
+		return "sha256"
+	case algByteScrypt:
+		return "scrypt"
+	case algByteEquihash:
+		return "equihash"
+	case algByteArgon2id:
+		return "argon2id"
+	default:
+		return ""
+	}
+}
+
 // Server handles TCP connections and processes client requests
 type Server struct {
-	listener   net.Listener
-	challenges domain.ChallengeService
-	quotes     domain.QuoteService
-	shutdownCh chan struct{}
+	listener              net.Listener
+	challenges            domain.ChallengeService
+	quotes                domain.QuoteService
+	complexityController  domain.AdaptiveComplexityController
+	maxConnections        int
+	activeConnections     atomic.Int64
+	cancel                context.CancelFunc
+	wg                    sync.WaitGroup
+	nextConnID            atomic.Uint64
 }
 
-// NewServer creates a new TCP server instance
-func NewServer(challenges domain.ChallengeService, quotes domain.QuoteService) *Server {
+// NewServer creates a new TCP server instance. complexityController may be
+// nil, in which case over-capacity rejections simply aren't reported to it.
+// maxConnections <= 0 means no limit: every accepted connection is
+// handled regardless of how many others are in flight
+func NewServer(challenges domain.ChallengeService, quotes domain.QuoteService, complexityController domain.AdaptiveComplexityController, maxConnections int) *Server {
 	return &Server{
-		challenges: challenges,
-		quotes:     quotes,
-		shutdownCh: make(chan struct{}),
+		challenges:           challenges,
+		quotes:               quotes,
+		complexityController: complexityController,
+		maxConnections:       maxConnections,
 	}
 }
 
-// Start begins listening for connections on the specified address
-func (s *Server) Start(address string) error {
+// Start begins listening for connections on the specified address. ctx is
+// the root context for every connection the server accepts: each handler
+// passes a context derived from it, which Stop also cancels, to the
+// ChallengeService and QuoteService calls it makes. The built-in Generate,
+// Verify and quote lookups, and the repositories behind them, do not check
+// that context, so cancelling it does not interrupt a call already under way
+func (s *Server) Start(ctx context.Context, address string) error {
 	listener, err := net.Listen("tcp", address)
 	if err != nil {
 		return fmt.Errorf("failed to start server: %w", err)
 	}
 	s.listener = listener
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
 	kit.Logger.Info("server started", "address", address)
 
 	for {
-		select {
-		case <-s.shutdownCh:
-			return nil
-		default:
-			conn, err := listener.Accept()
-			if err != nil {
-				kit.Logger.Error("failed to accept connection", err)
-				continue
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
 			}
-			go s.handleConnection(conn)
+			kit.Logger.Error("failed to accept connection", err)
+			continue
 		}
+
+		if s.overCapacity() {
+			kit.Logger.Info("rejecting connection over capacity", "remote_addr", conn.RemoteAddr(), "max_connections", s.maxConnections)
+			if s.complexityController != nil {
+				s.complexityController.RecordOutcome(conn.RemoteAddr().String(), domain.OutcomeRejected)
+			}
+			conn.Close()
+			continue
+		}
+
+		s.activeConnections.Add(1)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.activeConnections.Add(-1)
+			s.handleConnection(ctx, conn)
+		}()
 	}
 }
 
-// Stop gracefully shuts down the server
+// overCapacity reports whether accepting another connection would exceed
+// maxConnections. A non-positive maxConnections means no limit
+func (s *Server) overCapacity() bool {
+	return s.maxConnections > 0 && s.activeConnections.Load() >= int64(s.maxConnections)
+}
+
+// Stop gracefully shuts down the server: it cancels the context passed to
+// Start, closes the listener so Accept unblocks, and waits for every
+// in-flight handleConnection goroutine to return before returning itself
 func (s *Server) Stop() error {
-	close(s.shutdownCh)
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	var err error
 	if s.listener != nil {
-		return s.listener.Close()
+		err = s.listener.Close()
 	}
-	return nil
+
+	s.wg.Wait()
+	return err
 }
 
-func (s *Server) handleConnection(conn net.Conn) {
+func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 	defer conn.Close()
 
-	kit.Logger.Info("new connection", "remote_addr", conn.RemoteAddr())
+	// A per-connection logger correlates every line this connection emits
+	// (across potentially several log calls spanning reads and writes)
+	// without callers having to repeat the fields themselves
+	connID := s.nextConnID.Add(1)
+	log := kit.Logger.With("remote_addr", conn.RemoteAddr(), "conn_id", connID)
+
+	log.Info("new connection")
 
 	// Read command
 	if err := conn.SetReadDeadline(time.Now().Add(readTimeout)); err != nil {
-		kit.Logger.Error("failed to set read deadline", err)
+		log.Error("failed to set read deadline", err)
 		return
 	}
 
 	cmd := make([]byte, 1)
 	if _, err := io.ReadFull(conn, cmd); err != nil {
-		kit.Logger.Error("failed to read command", err)
+		log.Error("failed to read command", err)
+		return
+	}
+
+	// Read the one-byte content-type negotiation that follows the command.
+	// 0x00 = JSON, 0x01 = protobuf; an unrecognized byte falls back to JSON
+	ctByte := make([]byte, 1)
+	if _, err := io.ReadFull(conn, ctByte); err != nil {
+		log.Error("failed to read content-type byte", err)
 		return
 	}
+	encoding := ctByte[0]
+	log.Info("negotiated content type", "content_type", contentTypeName(encoding))
 
 	switch cmd[0] {
 	case cmdGetChallenge:
-		s.handleGetChallenge(conn)
+		s.handleGetChallenge(ctx, conn, encoding, log)
 	case cmdGetQuote:
-		s.handleGetQuote(conn)
+		s.handleGetQuote(ctx, conn, encoding, log)
 	default:
-		kit.Logger.Error("unknown command", fmt.Errorf("command: %d", cmd[0]))
-		s.writeError(conn, fmt.Errorf("unknown command: %d", cmd[0]))
+		log.Error("unknown command", fmt.Errorf("command: %d", cmd[0]))
+		s.writeError(conn, encoding, fmt.Errorf("unknown command: %d", cmd[0]), log)
 	}
 }
 
-func (s *Server) handleGetChallenge(conn net.Conn) {
-	challenge, err := s.challenges.Generate(context.Background())
+func (s *Server) handleGetChallenge(ctx context.Context, conn net.Conn, encoding byte, log kit.StructuredLogger) {
+	// Read the one-byte algorithm request that follows the content-type
+	// byte. 0x00 means "use the server's default algorithm"
+	if err := conn.SetReadDeadline(time.Now().Add(readTimeout)); err != nil {
+		log.Error("failed to set read deadline", err)
+		return
+	}
+
+	algByte := make([]byte, 1)
+	if _, err := io.ReadFull(conn, algByte); err != nil {
+		log.Error("failed to read algorithm byte", err)
+		s.writeError(conn, encoding, fmt.Errorf("failed to read algorithm byte: %w", err), log)
+		return
+	}
+
+	algorithm := algorithmName(algByte[0])
+	challenge, err := s.challenges.Generate(ctx, algorithm, conn.RemoteAddr().String())
 	if err != nil {
-		s.writeError(conn, err)
+		s.writeError(conn, encoding, err, log)
 		return
 	}
 
-	kit.Logger.Info("generated challenge", "id", challenge.ID())
-	s.writeResponse(conn, challenge)
+	log.Info("generated challenge", "id", challenge.ID(), "algorithm", challenge.Algorithm)
+	s.writeResponse(conn, encoding, challenge, log)
 }
 
-func (s *Server) handleGetQuote(conn net.Conn) {
+func (s *Server) handleGetQuote(ctx context.Context, conn net.Conn, encoding byte, log kit.StructuredLogger) {
 	// Set read deadline for the entire operation
 	if err := conn.SetReadDeadline(time.Now().Add(readTimeout)); err != nil {
-		kit.Logger.Error("failed to set read deadline", err)
+		log.Error("failed to set read deadline", err)
 		return
 	}
 
-	// Challenge ID is SHA-256 hash (32 bytes)
-	id := make([]byte, 32)
+	// The challenge identifier is length-prefixed (4-byte big-endian length,
+	// then that many bytes) since its format depends on the configured
+	// ChallengeService: a bare 32-byte Challenge.ID() for the
+	// repository-backed implementation, or a full, larger ChallengeToken
+	// for StatelessChallengeService
+	idLenBytes := make([]byte, 4)
+	if _, err := io.ReadFull(conn, idLenBytes); err != nil {
+		log.Error("failed to read challenge ID length", err)
+		s.writeError(conn, encoding, fmt.Errorf("failed to read challenge ID length: %w", err), log)
+		return
+	}
+	idLen := binary.BigEndian.Uint32(idLenBytes)
+	if idLen > maxChallengeIDLen {
+		log.Error("challenge ID too long", fmt.Errorf("length: %d", idLen))
+		s.writeError(conn, encoding, fmt.Errorf("challenge ID too long: %d bytes", idLen), log)
+		return
+	}
+
+	id := make([]byte, idLen)
 	if _, err := io.ReadFull(conn, id); err != nil {
-		kit.Logger.Error("failed to read challenge ID", err)
-		s.writeError(conn, fmt.Errorf("failed to read challenge ID: %w", err))
+		log.Error("failed to read challenge ID", err)
+		s.writeError(conn, encoding, fmt.Errorf("failed to read challenge ID: %w", err), log)
 		return
 	}
 
 	// Solution is 256-bit number (32 bytes)
 	solBytes := make([]byte, 32)
 	if _, err := io.ReadFull(conn, solBytes); err != nil {
-		kit.Logger.Error("failed to read solution", err)
-		s.writeError(conn, fmt.Errorf("failed to read solution: %w", err))
+		log.Error("failed to read solution", err)
+		s.writeError(conn, encoding, fmt.Errorf("failed to read solution: %w", err), log)
 		return
 	}
 
 	// Verify solution
-	if err := s.challenges.Verify(context.Background(), id, new(big.Int).SetBytes(solBytes)); err != nil {
-		kit.Logger.Error("invalid solution", err, "challenge_id", fmt.Sprintf("%x", id))
-		s.writeError(conn, err)
+	if err := s.challenges.Verify(ctx, id, new(big.Int).SetBytes(solBytes), conn.RemoteAddr().String()); err != nil {
+		log.Error("invalid solution", err, "challenge_id", fmt.Sprintf("%x", id))
+		s.writeError(conn, encoding, err, log)
 		return
 	}
 
 	// Get random quote
-	quote, err := s.quotes.GetRandomQuote(context.Background())
+	quote, err := s.quotes.GetRandomQuote(ctx)
 	if err != nil {
-		kit.Logger.Error("failed to get random quote", err)
-		s.writeError(conn, err)
+		log.Error("failed to get random quote", err)
+		s.writeError(conn, encoding, err, log)
 		return
 	}
 
-	kit.Logger.Info("sending quote", "challenge_id", fmt.Sprintf("%x", id))
-	s.writeResponse(conn, quote)
+	log.Info("sending quote", "challenge_id", fmt.Sprintf("%x", id))
+	s.writeResponse(conn, encoding, quote, log)
 }
 
-func (s *Server) writeResponse(conn net.Conn, data interface{}) {
+// writeResponse marshals data per encoding (contentTypeJSON or
+// contentTypeProtobuf) and writes it to conn behind the usual 4-byte
+// big-endian length prefix
+func (s *Server) writeResponse(conn net.Conn, encoding byte, data interface{}, log kit.StructuredLogger) {
 	// Set write deadline for the entire operation
 	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
-		kit.Logger.Error("failed to set write deadline", err)
+		log.Error("failed to set write deadline", err)
 		return
 	}
 
-	response, err := json.Marshal(data)
+	response, err := marshalResponse(encoding, data)
 	if err != nil {
-		kit.Logger.Error("failed to marshal response", err)
-		s.writeError(conn, fmt.Errorf("failed to marshal response: %w", err))
+		log.Error("failed to marshal response", err)
+		s.writeError(conn, encoding, fmt.Errorf("failed to marshal response: %w", err), log)
 		return
 	}
 
@@ -172,29 +305,24 @@ func (s *Server) writeResponse(conn net.Conn, data interface{}) {
 	binary.BigEndian.PutUint32(lenBytes, uint32(len(response)))
 	n, err := conn.Write(lenBytes)
 	if err != nil || n != 4 {
-		kit.Logger.Error("failed to write response length", "error", err, "bytes_written", n)
+		log.Error("failed to write response length", "error", err, "bytes_written", n)
 		return
 	}
 
 	// Write response data
 	n, err = conn.Write(response)
 	if err != nil || n != len(response) {
-		kit.Logger.Error("failed to write response", "error", err, "bytes_written", n, "expected_bytes", len(response))
+		log.Error("failed to write response", "error", err, "bytes_written", n, "expected_bytes", len(response))
 		return
 	}
 
 	// Reset write deadline after writing
 	if err := conn.SetWriteDeadline(time.Time{}); err != nil {
-		kit.Logger.Error("failed to reset write deadline", err)
+		log.Error("failed to reset write deadline", err)
 		return
 	}
 }
 
-func (s *Server) writeError(conn net.Conn, err error) {
-	response := struct {
-		Error string `json:"error"`
-	}{
-		Error: err.Error(),
-	}
-	s.writeResponse(conn, response)
+func (s *Server) writeError(conn net.Conn, encoding byte, err error, log kit.StructuredLogger) {
+	s.writeResponse(conn, encoding, errorResponse{Error: err.Error()}, log)
 }
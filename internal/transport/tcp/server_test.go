@@ -3,30 +3,55 @@ package tcp
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"io"
 	"math/big"
 	"net"
+	"sync"
 	"testing"
 	"time"
 
+	"word-of-wisdom/internal/difficulty"
 	"word-of-wisdom/internal/domain"
 	"word-of-wisdom/internal/kit"
+	"word-of-wisdom/internal/pow"
 	"word-of-wisdom/internal/repository"
 	"word-of-wisdom/internal/service"
+	"word-of-wisdom/internal/transport/tcp/pb"
 )
 
+// writeChallengeID writes the length-prefixed challenge identifier
+// handleGetQuote expects ahead of the solution: a 4-byte big-endian length
+// followed by id itself
+func writeChallengeID(conn net.Conn, id []byte) error {
+	lenBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(lenBytes, uint32(len(id)))
+	if _, err := conn.Write(lenBytes); err != nil {
+		return err
+	}
+	_, err := conn.Write(id)
+	return err
+}
+
 func TestServer(t *testing.T) {
 	// Create in-memory repositories
 	challengeRepo := repository.NewChallengeMemoryRepository()
 	quoteRepo := repository.NewQuoteMemoryRepository()
 
 	// Create services
+	// Fixed 16-bit complexity: cheap for Solve (about 65536 hashes on
+	// average), yet high enough that the all-zero solution the
+	// "Invalid Solution" case sends is almost never valid
+	complexityController := difficulty.NewController(big.NewInt(16), big.NewInt(16), time.Minute, 1)
 	challengeService := service.NewChallengeService(
 		challengeRepo,
-		[]byte("test-secret"),
-		big.NewInt(1), // easy complexity for tests
+		domain.NewChallengeVerifier(domain.NewStaticKeyring([]byte("test-secret"))),
+		complexityController,
 		5*time.Minute,
+		pow.DefaultProviders(domain.RealClock{}),
+		pow.AlgorithmSHA256,
+		5,
 	)
 	quoteService := service.NewQuoteService(quoteRepo)
 
@@ -70,9 +95,9 @@ func TestServer(t *testing.T) {
 	}
 
 	// Create and start server
-	server := NewServer(challengeService, quoteService)
+	server := NewServer(challengeService, quoteService, complexityController, 0)
 	go func() {
-		if err := server.Start(":0"); err != nil {
+		if err := server.Start(context.Background(), ":0"); err != nil {
 			t.Errorf("Server failed: %v", err)
 		}
 	}()
@@ -90,7 +115,7 @@ func TestServer(t *testing.T) {
 		defer conn.Close()
 
 		// Send get challenge request
-		if _, err := conn.Write([]byte{0x01}); err != nil { // 0x01 = get challenge
+		if _, err := conn.Write([]byte{0x01, 0x00, 0x00}); err != nil { // 0x01 = get challenge, 0x00 = JSON, 0x00 = default algorithm
 			t.Fatalf("Failed to send challenge request: %v", err)
 		}
 
@@ -130,12 +155,12 @@ func TestServer(t *testing.T) {
 		}
 
 		// Send get quote request
-		if _, err := conn.Write([]byte{0x02}); err != nil { // 0x02 = get quote
+		if _, err := conn.Write([]byte{0x02, 0x00}); err != nil { // 0x02 = get quote, 0x00 = JSON
 			t.Fatalf("Failed to send quote request: %v", err)
 		}
 
-		// Send the original challenge ID (32 bytes)
-		if _, err := conn.Write(challengeID); err != nil {
+		// Send the original challenge ID, length-prefixed
+		if err := writeChallengeID(conn, challengeID); err != nil {
 			t.Fatalf("Failed to send challenge ID: %v", err)
 		}
 
@@ -183,13 +208,13 @@ func TestServer(t *testing.T) {
 		defer conn.Close()
 
 		// Send get quote request
-		if _, err := conn.Write([]byte{0x02}); err != nil {
+		if _, err := conn.Write([]byte{0x02, 0x00}); err != nil {
 			t.Fatalf("Failed to send quote request: %v", err)
 		}
 
 		// Send invalid challenge ID
 		invalidID := bytes.Repeat([]byte{0x00}, 32)
-		if _, err := conn.Write(invalidID); err != nil {
+		if err := writeChallengeID(conn, invalidID); err != nil {
 			t.Fatalf("Failed to send challenge ID: %v", err)
 		}
 
@@ -233,7 +258,7 @@ func TestServer(t *testing.T) {
 		defer conn.Close()
 
 		// Send get challenge request
-		if _, err := conn.Write([]byte{0x01}); err != nil {
+		if _, err := conn.Write([]byte{0x01, 0x00, 0x00}); err != nil { // 0x00 = JSON, 0x00 = default algorithm
 			t.Fatalf("Failed to send challenge request: %v", err)
 		}
 
@@ -266,12 +291,12 @@ func TestServer(t *testing.T) {
 		}
 
 		// Send get quote request
-		if _, err := conn.Write([]byte{0x02}); err != nil {
+		if _, err := conn.Write([]byte{0x02, 0x00}); err != nil {
 			t.Fatalf("Failed to send quote request: %v", err)
 		}
 
-		// Send the challenge ID
-		if _, err := conn.Write(challengeID); err != nil {
+		// Send the challenge ID, length-prefixed
+		if err := writeChallengeID(conn, challengeID); err != nil {
 			t.Fatalf("Failed to send challenge ID: %v", err)
 		}
 
@@ -311,8 +336,120 @@ func TestServer(t *testing.T) {
 		}
 	})
 
+	t.Run("Protobuf Encoding", func(t *testing.T) {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		defer conn.Close()
+
+		// Send get challenge request, negotiating protobuf
+		if _, err := conn.Write([]byte{0x01, 0x01, 0x00}); err != nil { // 0x01 = get challenge, 0x01 = protobuf, 0x00 = default algorithm
+			t.Fatalf("Failed to send challenge request: %v", err)
+		}
+
+		lenBuf := make([]byte, 4)
+		if _, err := io.ReadFull(conn, lenBuf); err != nil {
+			t.Fatalf("Failed to read response length: %v", err)
+		}
+		responseLen := int(lenBuf[0])<<24 | int(lenBuf[1])<<16 | int(lenBuf[2])<<8 | int(lenBuf[3])
+
+		responseBuf := make([]byte, responseLen)
+		if _, err := io.ReadFull(conn, responseBuf); err != nil {
+			t.Fatalf("Failed to read response: %v", err)
+		}
+
+		var pbChallenge pb.Challenge
+		if err := pbChallenge.Unmarshal(responseBuf); err != nil {
+			t.Fatalf("Failed to unmarshal protobuf challenge: %v", err)
+		}
+		if len(pbChallenge.Nonce) == 0 {
+			t.Error("Expected a non-empty nonce in the protobuf challenge")
+		}
+	})
+
 	// Cleanup
 	if err := server.Stop(); err != nil {
 		t.Fatalf("Failed to stop server: %v", err)
 	}
 }
+
+// fakeComplexityController records every RecordOutcome call so a test can
+// assert on what the server reported, without depending on
+// difficulty.Controller's retargeting behavior
+type fakeComplexityController struct {
+	mu       sync.Mutex
+	outcomes []domain.Outcome
+}
+
+func (f *fakeComplexityController) Complexity(clientAddr string) *big.Int { return big.NewInt(1) }
+
+func (f *fakeComplexityController) RecordOutcome(clientAddr string, outcome domain.Outcome) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.outcomes = append(f.outcomes, outcome)
+}
+
+func (f *fakeComplexityController) Current() *big.Int { return big.NewInt(1) }
+
+func (f *fakeComplexityController) recordedOutcomes() []domain.Outcome {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]domain.Outcome(nil), f.outcomes...)
+}
+
+// TestServer_RejectsOverCapacity verifies a connection accepted beyond
+// maxConnections is closed without being served and reported to the
+// complexity controller as domain.OutcomeRejected, feeding connection floods
+// into PoW retargeting the same way failed solutions do
+func TestServer_RejectsOverCapacity(t *testing.T) {
+	challengeRepo := repository.NewChallengeMemoryRepository()
+	quoteRepo := repository.NewQuoteMemoryRepository()
+	complexityController := &fakeComplexityController{}
+	challengeService := service.NewChallengeService(
+		challengeRepo,
+		domain.NewChallengeVerifier(domain.NewStaticKeyring([]byte("test-secret"))),
+		difficulty.NewController(big.NewInt(1), big.NewInt(1), time.Minute, 1),
+		5*time.Minute,
+		pow.DefaultProviders(domain.RealClock{}),
+		pow.AlgorithmSHA256,
+		5,
+	)
+	quoteService := service.NewQuoteService(quoteRepo)
+
+	server := NewServer(challengeService, quoteService, complexityController, 1)
+	go func() {
+		if err := server.Start(context.Background(), ":0"); err != nil {
+			t.Errorf("Server failed: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond) // wait for server to start
+	defer server.Stop()
+
+	addr := server.listener.Addr().String()
+
+	// The only slot is occupied by a connection that never sends a command,
+	// so it stays open (blocked reading) for the rest of the test
+	blocker, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer blocker.Close()
+	time.Sleep(100 * time.Millisecond) // let the accept loop count it as active
+
+	rejected, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer rejected.Close()
+
+	buf := make([]byte, 1)
+	if n, err := rejected.Read(buf); err != io.EOF || n != 0 {
+		t.Errorf("expected the rejected connection to be closed without a response, got n=%d err=%v", n, err)
+	}
+
+	outcomes := complexityController.recordedOutcomes()
+	if len(outcomes) != 1 || outcomes[0] != domain.OutcomeRejected {
+		t.Errorf("expected exactly one OutcomeRejected to be recorded, got %v", outcomes)
+	}
+}